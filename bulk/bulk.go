@@ -0,0 +1,313 @@
+// Package bulk generates the charts of many images concurrently.
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charrapp/charrapp/chart"
+	"github.com/charrapp/charrapp/source"
+)
+
+// DefaultWorkers is the number of images processed concurrently if Options.Workers is not set.
+const DefaultWorkers = 4
+
+// Status is the outcome of generating the chart of a single image.
+type Status string
+
+const (
+	// StatusNew is reported for charts generated for the first time.
+	StatusNew Status = "new"
+	// StatusUpdated is reported for charts regenerated because their inputs changed.
+	StatusUpdated Status = "updated"
+	// StatusUnchanged is reported for charts whose inputs did not change since the last run.
+	StatusUnchanged Status = "unchanged"
+	// StatusRemoved is reported for images recorded in the state which the source no longer provides.
+	StatusRemoved Status = "removed"
+	StatusSkipped Status = "skipped"
+	StatusFailed  Status = "failed"
+)
+
+// DeprecationPolicy decides how images deprecated upstream are charted.
+type DeprecationPolicy string
+
+const (
+	// DeprecationSkip does not chart deprecated images.
+	DeprecationSkip DeprecationPolicy = "skip"
+	// DeprecationFlag marks charts of deprecated images as deprecated.
+	DeprecationFlag DeprecationPolicy = "flag"
+	// DeprecationIgnore charts deprecated images like any other image.
+	DeprecationIgnore DeprecationPolicy = "ignore"
+)
+
+// ParseDeprecationPolicy parses the name of a DeprecationPolicy.
+func ParseDeprecationPolicy(s string) (DeprecationPolicy, error) {
+	switch policy := DeprecationPolicy(s); policy {
+	case DeprecationSkip, DeprecationFlag, DeprecationIgnore:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown deprecation policy %q, expected one of %s, %s or %s", s, DeprecationSkip, DeprecationFlag, DeprecationIgnore)
+	}
+}
+
+// Options configures a bulk run.
+type Options struct {
+	// Workers is the maximum number of images processed concurrently.
+	Workers int
+
+	// Templates is the chart template tree.
+	Templates fs.FS
+
+	// Write stores the generated chart of an image. Calls are serialized, so
+	// it does not have to be safe for concurrent use.
+	Write func(image string, files map[string][]byte) error
+
+	// Progress is called after every image if set. Calls are serialized.
+	Progress func(result *Result)
+
+	// State records the inputs of previously generated charts. Charts whose
+	// inputs did not change are not regenerated, and the state is updated for
+	// every chart written. Without a state every chart is generated as new.
+	State *State
+
+	// Force regenerates charts even if their inputs did not change.
+	Force bool
+
+	// VersionPolicy decides how chart versions are bumped. If nil,
+	// DefaultVersionPolicy is used.
+	VersionPolicy *VersionPolicy
+
+	// Deprecation decides how images deprecated upstream are charted. The zero
+	// value is DeprecationFlag.
+	Deprecation DeprecationPolicy
+
+	// Published returns the highest version of the named chart which is
+	// already published, nil if there is none. Chart versions are bumped from
+	// it if it is newer than the version in the state, so a lost state never
+	// re-issues a published version. It may be nil.
+	Published func(name string) *chart.Metadata
+
+	// Maintainers are listed in the Chart.yaml of every chart.
+	Maintainers []*chart.Maintainer
+
+	// Prune reports images recorded in the state but missing from the run as
+	// removed and drops them from the state. Set it only if the run covers
+	// every image of the source.
+	Prune bool
+}
+
+// Run generates the charts of all images, continuing past individual
+// failures. Once ctx is canceled, requests in flight are aborted and all
+// images not yet written are reported as skipped.
+func Run(ctx context.Context, src source.Source, images []string, opts Options) *Report {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	report := &Report{
+		Started: time.Now().UTC(),
+		Results: make([]*Result, len(images)),
+	}
+
+	templateHash, err := chart.HashTemplates(opts.Templates)
+	if err != nil {
+		for i, image := range images {
+			report.Results[i] = &Result{Image: image, Status: StatusFailed, Reason: err.Error()}
+		}
+		report.Finished = time.Now().UTC()
+		return report
+	}
+
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result := generate(ctx, src, images[i], templateHash, opts, &mu)
+
+				mu.Lock()
+				report.Results[i] = result
+				if opts.Progress != nil {
+					opts.Progress(result)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range images {
+		if ctx.Err() != nil {
+			report.Results[i] = &Result{Image: images[i], Status: StatusSkipped, Reason: ctx.Err().Error()}
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			report.Results[i] = &Result{Image: images[i], Status: StatusSkipped, Reason: ctx.Err().Error()}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if opts.Prune && opts.State != nil {
+		for _, image := range opts.State.removed(images) {
+			result := &Result{Image: image, Status: StatusRemoved, Version: opts.State.Images[image].Version}
+			delete(opts.State.Images, image)
+			report.Results = append(report.Results, result)
+			if opts.Progress != nil {
+				opts.Progress(result)
+			}
+		}
+	}
+
+	report.Finished = time.Now().UTC()
+	return report
+}
+
+func deprecationReason(message string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	if message == "" {
+		return "deprecated upstream"
+	}
+	return "deprecated upstream: " + message
+}
+
+// generate creates and writes the chart of a single image unless its inputs
+// are unchanged. mu serializes calls to opts.Write and opts.Published and
+// access to opts.State.
+func generate(ctx context.Context, src source.Source, image string, templateHash string, opts Options, mu *sync.Mutex) *Result {
+	start := time.Now()
+	result := &Result{Image: image}
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	if err := ctx.Err(); err != nil {
+		result.Status = StatusSkipped
+		result.Reason = err.Error()
+		return result
+	}
+
+	chartData, err := source.ChartData(ctx, src, image)
+	if err != nil && ctx.Err() != nil {
+		result.Status = StatusSkipped
+		result.Reason = ctx.Err().Error()
+		return result
+	}
+	if errors.Is(err, source.ErrNoVersions) {
+		result.Status = StatusSkipped
+		result.Reason = err.Error()
+		return result
+	}
+	if err != nil {
+		result.Status = StatusFailed
+		result.Reason = err.Error()
+		return result
+	}
+	result.Version = chartData.Version
+	chartData.Maintainers = opts.Maintainers
+	result.Warnings = chartData.Warnings()
+
+	if chartData.Config.ProjectDeprecationStatus {
+		result.Deprecated = true
+		switch opts.Deprecation {
+		case DeprecationSkip:
+			result.Status = StatusSkipped
+			result.Reason = deprecationReason(chartData.Config.ProjectDeprecationMessage)
+			return result
+		case DeprecationIgnore:
+			chartData.Deprecated = false
+		default:
+			chartData.Deprecated = true
+		}
+		result.Warnings = append(result.Warnings, deprecationReason(chartData.Config.ProjectDeprecationMessage))
+	}
+
+	metadataHash, err := chartData.MetadataHash()
+	if err != nil {
+		result.Status = StatusFailed
+		result.Reason = err.Error()
+		return result
+	}
+	entry := &StateEntry{
+		Tag:          chartData.Tag,
+		Version:      chartData.Version,
+		MetadataHash: metadataHash,
+		TemplateHash: templateHash,
+	}
+
+	var previous *StateEntry
+	if opts.State != nil {
+		mu.Lock()
+		previous = opts.State.Images[image]
+		mu.Unlock()
+	}
+
+	switch {
+	case previous == nil:
+		result.Status = StatusNew
+	case previous.unchanged(entry):
+		if !opts.Force {
+			result.Status = StatusUnchanged
+			result.ChartVersion = previous.ChartVersion
+			return result
+		}
+		// The chart is generated from the same inputs, so it keeps its version.
+		result.Status = StatusUpdated
+		entry.ChartVersion = previous.ChartVersion
+	default:
+		result.Status = StatusUpdated
+	}
+
+	if entry.ChartVersion == "" {
+		policy := DefaultVersionPolicy
+		if opts.VersionPolicy != nil {
+			policy = *opts.VersionPolicy
+		}
+		last := previous
+		if opts.Published != nil {
+			mu.Lock()
+			last = lastPublished(last, opts.Published(chartData.Config.ProjectName))
+			mu.Unlock()
+		}
+		entry.ChartVersion, err = policy.chartVersion(last, entry)
+		if err != nil {
+			result.Status = StatusFailed
+			result.Reason = err.Error()
+			return result
+		}
+	}
+	chartData.ChartVersion = entry.ChartVersion
+	result.ChartVersion = entry.ChartVersion
+
+	files, err := chartData.GenerateChart(opts.Templates)
+	if err != nil {
+		result.Status = StatusFailed
+		result.Reason = err.Error()
+		return result
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if err := opts.Write(image, files); err != nil {
+		result.Status = StatusFailed
+		result.Reason = err.Error()
+		return result
+	}
+
+	if opts.State != nil {
+		entry.Generated = time.Now().UTC()
+		opts.State.Images[image] = entry
+	}
+	return result
+}
@@ -0,0 +1,216 @@
+package bulk
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/chart"
+	"github.com/charrapp/charrapp/lsio/lsiotest"
+	"github.com/charrapp/charrapp/parser"
+	"github.com/charrapp/charrapp/source"
+	charttemplate "github.com/charrapp/charrapp/template"
+)
+
+const fixtures = "../lsio/testdata"
+
+func TestRun(t *testing.T) {
+	src := lsiotest.New(fixtures)
+	written := make(map[string]bool)
+
+	report := Run(context.Background(), src, []string{"plex", "missing", "untagged", "wireguard"}, Options{
+		Workers:   2,
+		Templates: charttemplate.FS,
+		Write: func(image string, files map[string][]byte) error {
+			written[image] = len(files) > 0
+			return nil
+		},
+	})
+
+	statuses := make([]Status, len(report.Results))
+	for i, result := range report.Results {
+		statuses[i] = result.Status
+	}
+	testza.AssertEqual(t, []Status{StatusNew, StatusFailed, StatusSkipped, StatusNew}, statuses)
+	testza.AssertEqual(t, map[string]bool{"plex": true, "wireguard": true}, written)
+	testza.AssertEqual(t, "1.0.20210914", report.Results[3].Version)
+	testza.AssertContains(t, report.Results[1].Reason, "failed opening fixture")
+
+	markdown := string(report.Markdown())
+	testza.AssertContains(t, markdown, "| untagged | skipped |  |  | found 0 versions |")
+
+	b, err := report.JSON()
+	testza.AssertNoError(t, err)
+	testza.AssertContains(t, string(b), `"status": "failed"`)
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := Run(ctx, lsiotest.New(fixtures), []string{"plex", "sonarr"}, Options{
+		Templates: charttemplate.FS,
+		Write: func(string, map[string][]byte) error {
+			t.Error("no chart should be written")
+			return nil
+		},
+	})
+
+	testza.AssertEqual(t, 2, report.Count(StatusSkipped))
+	testza.AssertTrue(t, strings.Contains(report.Summary(), "0 new, 0 updated, 0 unchanged, 0 removed, 2 skipped, 0 failed"))
+}
+
+// cancelingSource cancels the run while the config of an image is fetched.
+type cancelingSource struct {
+	source.Source
+	cancel context.CancelFunc
+}
+
+func (s cancelingSource) Config(ctx context.Context, image string, version string) (*parser.Config, error) {
+	s.cancel()
+	return s.Source.Config(ctx, image, version)
+}
+
+func TestRunCanceledInFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	report := Run(ctx, cancelingSource{lsiotest.New(fixtures), cancel}, []string{"plex"}, Options{
+		Templates: charttemplate.FS,
+		Write: func(string, map[string][]byte) error {
+			t.Error("no chart should be written")
+			return nil
+		},
+	})
+
+	testza.AssertEqual(t, 1, report.Count(StatusSkipped))
+	testza.AssertEqual(t, context.Canceled.Error(), report.Results[0].Reason)
+}
+
+func TestRunState(t *testing.T) {
+	src := lsiotest.New(fixtures)
+	state := NewState()
+	state.Images["gone"] = &StateEntry{Tag: "1.0.0-ls1", Version: "1.0.0"}
+
+	var written []string
+	opts := Options{
+		Workers:   1,
+		Templates: charttemplate.FS,
+		Write: func(image string, _ map[string][]byte) error {
+			written = append(written, image)
+			return nil
+		},
+		State: state,
+		Prune: true,
+	}
+	statuses := func(report *Report) map[string]Status {
+		m := make(map[string]Status)
+		for _, result := range report.Results {
+			m[result.Image] = result.Status
+		}
+		return m
+	}
+
+	report := Run(context.Background(), src, []string{"plex", "wireguard"}, opts)
+	testza.AssertEqual(t, map[string]Status{"plex": StatusNew, "wireguard": StatusNew, "gone": StatusRemoved}, statuses(report))
+	testza.AssertEqual(t, []string{"plex", "wireguard"}, written)
+	testza.AssertEqual(t, "1.32.5.7349-8f4248874-ls185", state.Images["plex"].Tag)
+	testza.AssertNil(t, state.Images["gone"])
+
+	// Round trip the state as it would be between runs.
+	b, err := state.Marshal()
+	testza.AssertNoError(t, err)
+	opts.State, err = LoadState(b)
+	testza.AssertNoError(t, err)
+
+	written = nil
+	opts.State.Images["wireguard"].MetadataHash = "outdated"
+	report = Run(context.Background(), src, []string{"plex", "wireguard"}, opts)
+	testza.AssertEqual(t, map[string]Status{"plex": StatusUnchanged, "wireguard": StatusUpdated}, statuses(report))
+	testza.AssertEqual(t, []string{"wireguard"}, written)
+	testza.AssertEqual(t, "1.0.20210915", opts.State.Images["wireguard"].ChartVersion)
+
+	written = nil
+	opts.Force = true
+	report = Run(context.Background(), src, []string{"plex"}, opts)
+	testza.AssertEqual(t, StatusUpdated, report.Results[0].Status)
+	testza.AssertEqual(t, []string{"plex"}, written)
+	// Forced regeneration from the same inputs keeps the chart version.
+	testza.AssertEqual(t, "32.5.7349", report.Results[0].ChartVersion)
+}
+
+func TestRunPublished(t *testing.T) {
+	// Without state the chart would be versioned like the app, which is already published.
+	report := Run(context.Background(), lsiotest.New(fixtures), []string{"plex"}, Options{
+		Templates: charttemplate.FS,
+		Write:     func(string, map[string][]byte) error { return nil },
+		State:     NewState(),
+		Published: func(name string) *chart.Metadata {
+			testza.AssertEqual(t, "plex", name)
+			return &chart.Metadata{Name: name, Version: "32.5.7349", AppVersion: "32.5.7349"}
+		},
+	})
+
+	testza.AssertEqual(t, StatusNew, report.Results[0].Status)
+	testza.AssertEqual(t, "32.5.7350", report.Results[0].ChartVersion)
+}
+
+func TestReportWarnings(t *testing.T) {
+	report := &Report{Results: []*Result{
+		{Image: "plex", Status: StatusNew, Warnings: []string{"param_mac_address is ignored"}},
+		{Image: "sonarr", Status: StatusNew},
+	}}
+	testza.AssertContains(t, string(report.Markdown()), "## Warnings\n\n- plex: param_mac_address is ignored\n")
+}
+
+// deprecatedSource reports every image of the wrapped source as deprecated upstream.
+type deprecatedSource struct {
+	source.Source
+}
+
+func (s deprecatedSource) Config(ctx context.Context, image string, version string) (*parser.Config, error) {
+	config, err := s.Source.Config(ctx, image, version)
+	if err != nil {
+		return nil, err
+	}
+	config.ProjectDeprecationStatus = true
+	config.ProjectDeprecationMessage = "Use the official image instead."
+	return config, nil
+}
+
+func TestRunDeprecated(t *testing.T) {
+	src := deprecatedSource{lsiotest.New(fixtures)}
+
+	for _, test := range []struct {
+		policy     DeprecationPolicy
+		status     Status
+		deprecated bool
+	}{
+		{DeprecationSkip, StatusSkipped, false},
+		{DeprecationFlag, StatusNew, true},
+		{DeprecationIgnore, StatusNew, false},
+	} {
+		t.Run(string(test.policy), func(t *testing.T) {
+			var chartYAML string
+			report := Run(context.Background(), src, []string{"sonarr"}, Options{
+				Templates: charttemplate.FS,
+				Write: func(_ string, files map[string][]byte) error {
+					chartYAML = string(files["Chart.yaml"])
+					return nil
+				},
+				Deprecation: test.policy,
+			})
+
+			result := report.Results[0]
+			testza.AssertEqual(t, test.status, result.Status)
+			testza.AssertTrue(t, result.Deprecated)
+			testza.AssertEqual(t, test.deprecated, strings.Contains(chartYAML, "deprecated: true"))
+			testza.AssertContains(t, report.Summary(), "1 deprecated upstream")
+		})
+	}
+
+	_, err := ParseDeprecationPolicy("drop")
+	testza.AssertNotNil(t, err)
+}
@@ -0,0 +1,100 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/charrapp/charrapp/chart"
+)
+
+// Result is the outcome of generating the chart of a single image.
+type Result struct {
+	Image        string        `json:"image"`
+	Status       Status        `json:"status"`
+	Version      string        `json:"version,omitempty"`
+	ChartVersion string        `json:"chartVersion,omitempty"`
+	Reason       string        `json:"reason,omitempty"`
+	Warnings     []string      `json:"warnings,omitempty"`
+	Deprecated   bool          `json:"deprecated,omitempty"`
+	Duration     time.Duration `json:"duration"`
+}
+
+// Report summarizes a bulk run, listing results in the order images were given.
+type Report struct {
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Results  []*Result `json:"results"`
+}
+
+// Count returns the number of results with the given status.
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Deprecated returns the number of results for images deprecated upstream.
+func (r *Report) Deprecated() int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Deprecated {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary is a one-line overview of the run.
+func (r *Report) Summary() string {
+	if n := r.Deprecated(); n > 0 {
+		return fmt.Sprintf("%s, %d deprecated upstream", r.summary(), n)
+	}
+	return r.summary()
+}
+
+func (r *Report) summary() string {
+	return fmt.Sprintf("%d new, %d updated, %d unchanged, %d removed, %d skipped, %d failed in %s",
+		r.Count(StatusNew), r.Count(StatusUpdated), r.Count(StatusUnchanged), r.Count(StatusRemoved),
+		r.Count(StatusSkipped), r.Count(StatusFailed),
+		r.Finished.Sub(r.Started).Round(time.Millisecond))
+}
+
+// JSON encodes the report as JSON.
+func (r *Report) JSON() ([]byte, error) {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding report: %w", err)
+	}
+	return append(b, '\n'), nil
+}
+
+// Markdown renders the report as a Markdown table.
+func (r *Report) Markdown() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Chart generation report\n\n%s.\n\n", r.Summary())
+	b.WriteString("| Image | Status | Version | Chart version | Reason |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, result := range r.Results {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			chart.MarkdownCell(result.Image), result.Status, chart.MarkdownCell(result.Version),
+			chart.MarkdownCell(result.ChartVersion), chart.MarkdownCell(result.Reason))
+	}
+
+	first := true
+	for _, result := range r.Results {
+		for _, warning := range result.Warnings {
+			if first {
+				b.WriteString("\n## Warnings\n\n")
+				first = false
+			}
+			fmt.Fprintf(&b, "- %s: %s\n", result.Image, warning)
+		}
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,77 @@
+package bulk
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StateFile is the default name of the file recording the inputs of the last generated charts.
+const StateFile = "charrapp-state.json"
+
+// State records, per image, the inputs its chart was last generated from, so
+// later runs only regenerate charts whose inputs changed.
+type State struct {
+	Images map[string]*StateEntry `json:"images"`
+}
+
+// StateEntry describes the inputs of the last generated chart of an image.
+// MetadataHash is chart.Data.MetadataHash, which covers the parsed readme-vars
+// config rather than the fetched readme-vars file.
+type StateEntry struct {
+	Tag          string    `json:"tag"`
+	Version      string    `json:"version"`
+	ChartVersion string    `json:"chartVersion,omitempty"`
+	MetadataHash string    `json:"metadataHash"`
+	TemplateHash string    `json:"templateHash"`
+	Generated    time.Time `json:"generated"`
+}
+
+// unchanged reports whether the entry was generated from the same inputs as other.
+func (e *StateEntry) unchanged(other *StateEntry) bool {
+	return e.Tag == other.Tag && e.MetadataHash == other.MetadataHash && e.TemplateHash == other.TemplateHash
+}
+
+// NewState returns an empty state.
+func NewState() *State {
+	return &State{Images: make(map[string]*StateEntry)}
+}
+
+// LoadState parses a state file.
+func LoadState(b []byte) (*State, error) {
+	state := NewState()
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, fmt.Errorf("failed parsing state: %w", err)
+	}
+	if state.Images == nil {
+		state.Images = make(map[string]*StateEntry)
+	}
+	return state, nil
+}
+
+// Marshal encodes the state as JSON.
+func (s *State) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding state: %w", err)
+	}
+	return append(b, '\n'), nil
+}
+
+// removed returns the sorted images recorded in the state but missing from images.
+func (s *State) removed(images []string) []string {
+	present := make(map[string]bool, len(images))
+	for _, image := range images {
+		present[image] = true
+	}
+
+	var removed []string
+	for image := range s.Images {
+		if !present[image] {
+			removed = append(removed, image)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
@@ -0,0 +1,134 @@
+package bulk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/charrapp/charrapp/chart"
+)
+
+// Bump is the part of a chart version which is incremented.
+type Bump string
+
+const (
+	BumpMajor Bump = "major"
+	BumpMinor Bump = "minor"
+	BumpPatch Bump = "patch"
+)
+
+// VersionPolicy decides how the chart version is bumped when the app version
+// changes, depending on which part of the app version changed. A changed tag
+// with the same app version, e.g. a new build, counts as a patch change.
+// Charts regenerated for the same tag always get a patch bump.
+type VersionPolicy struct {
+	Major Bump
+	Minor Bump
+	Patch Bump
+}
+
+// DefaultVersionPolicy bumps the same part of the chart version as changed in the app version.
+var DefaultVersionPolicy = VersionPolicy{
+	Major: BumpMajor,
+	Minor: BumpMinor,
+	Patch: BumpPatch,
+}
+
+// ParseVersionPolicy parses rules of the form "major=major,minor=minor,patch=patch",
+// mapping the changed part of the app version to the part of the chart version
+// to bump. Parts without a rule use DefaultVersionPolicy.
+func ParseVersionPolicy(s string) (VersionPolicy, error) {
+	policy := DefaultVersionPolicy
+	if strings.TrimSpace(s) == "" {
+		return policy, nil
+	}
+
+	for _, rule := range strings.Split(s, ",") {
+		app, bump, ok := strings.Cut(strings.TrimSpace(rule), "=")
+		if !ok {
+			return policy, fmt.Errorf("invalid version rule %q, expected <app part>=<chart part>", rule)
+		}
+
+		b := Bump(bump)
+		if b != BumpMajor && b != BumpMinor && b != BumpPatch {
+			return policy, fmt.Errorf("invalid version rule %q: unknown chart part %q", rule, bump)
+		}
+
+		switch Bump(app) {
+		case BumpMajor:
+			policy.Major = b
+		case BumpMinor:
+			policy.Minor = b
+		case BumpPatch:
+			policy.Patch = b
+		default:
+			return policy, fmt.Errorf("invalid version rule %q: unknown app part %q", rule, app)
+		}
+	}
+	return policy, nil
+}
+
+// lastPublished returns published as the entry to bump from if it is newer
+// than previous, and previous otherwise.
+func lastPublished(previous *StateEntry, published *chart.Metadata) *StateEntry {
+	if published == nil {
+		return previous
+	}
+	publishedVersion, err := semver.NewVersion(published.Version)
+	if err != nil {
+		return previous
+	}
+	if previous != nil {
+		last := previous.ChartVersion
+		if last == "" {
+			last = previous.Version
+		}
+		if v, err := semver.NewVersion(last); err == nil && !publishedVersion.GreaterThan(v) {
+			return previous
+		}
+	}
+	return &StateEntry{Version: published.AppVersion, ChartVersion: published.Version}
+}
+
+// chartVersion returns the chart version for entry, which replaces previous.
+// The first chart of an image is versioned like the app.
+func (p VersionPolicy) chartVersion(previous *StateEntry, entry *StateEntry) (string, error) {
+	if previous == nil {
+		return entry.Version, nil
+	}
+
+	last := previous.ChartVersion
+	if last == "" {
+		last = previous.Version
+	}
+	chartVersion, err := semver.NewVersion(last)
+	if err != nil {
+		return "", fmt.Errorf("invalid chart version %q in state: %w", last, err)
+	}
+
+	bump := BumpPatch
+	if previous.Tag != entry.Tag {
+		bump = p.Patch
+		oldApp, oldErr := semver.NewVersion(previous.Version)
+		newApp, newErr := semver.NewVersion(entry.Version)
+		switch {
+		case oldErr != nil || newErr != nil:
+		case oldApp.Major() != newApp.Major():
+			bump = p.Major
+		case oldApp.Minor() != newApp.Minor():
+			bump = p.Minor
+		}
+	}
+
+	var next semver.Version
+	switch bump {
+	case BumpMajor:
+		next = chartVersion.IncMajor()
+	case BumpMinor:
+		next = chartVersion.IncMinor()
+	default:
+		next = chartVersion.IncPatch()
+	}
+	return next.String(), nil
+}
@@ -0,0 +1,65 @@
+package bulk
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/chart"
+)
+
+func TestChartVersion(t *testing.T) {
+	previous := &StateEntry{Tag: "1.2.3-ls10", Version: "1.2.3", ChartVersion: "1.2.5"}
+
+	tests := []struct {
+		name   string
+		policy string
+		entry  *StateEntry
+		want   string
+	}{
+		{"template change", "", &StateEntry{Tag: "1.2.3-ls10", Version: "1.2.3"}, "1.2.6"},
+		{"new build", "", &StateEntry{Tag: "1.2.3-ls11", Version: "1.2.3"}, "1.2.6"},
+		{"app patch", "", &StateEntry{Tag: "1.2.4-ls11", Version: "1.2.4"}, "1.2.6"},
+		{"app minor", "", &StateEntry{Tag: "1.3.0-ls11", Version: "1.3.0"}, "1.3.0"},
+		{"app major", "", &StateEntry{Tag: "2.0.0-ls11", Version: "2.0.0"}, "2.0.0"},
+		{"app major as minor", "major=minor", &StateEntry{Tag: "2.0.0-ls11", Version: "2.0.0"}, "1.3.0"},
+		{"app patch as minor", "patch=minor", &StateEntry{Tag: "1.2.4-ls11", Version: "1.2.4"}, "1.3.0"},
+		{"template change ignores policy", "patch=major", &StateEntry{Tag: "1.2.3-ls10", Version: "1.2.3"}, "1.2.6"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := ParseVersionPolicy(test.policy)
+			testza.AssertNoError(t, err)
+			got, err := policy.chartVersion(previous, test.entry)
+			testza.AssertNoError(t, err)
+			testza.AssertEqual(t, test.want, got)
+		})
+	}
+
+	got, err := DefaultVersionPolicy.chartVersion(nil, &StateEntry{Version: "4.5.6"})
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "4.5.6", got)
+}
+
+func TestParseVersionPolicy(t *testing.T) {
+	policy, err := ParseVersionPolicy("major=minor, patch=minor")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, VersionPolicy{Major: BumpMinor, Minor: BumpMinor, Patch: BumpMinor}, policy)
+
+	for _, s := range []string{"major", "major=huge", "build=patch"} {
+		_, err := ParseVersionPolicy(s)
+		testza.AssertNotNil(t, err, s)
+	}
+}
+
+func TestLastPublished(t *testing.T) {
+	previous := &StateEntry{Tag: "1.2.3-ls10", Version: "1.2.3", ChartVersion: "1.2.5"}
+
+	testza.AssertEqual(t, previous, lastPublished(previous, nil))
+	testza.AssertEqual(t, previous, lastPublished(previous, &chart.Metadata{Version: "1.2.5", AppVersion: "1.2.3"}))
+	testza.AssertEqual(t, &StateEntry{Version: "1.2.3", ChartVersion: "1.2.7"},
+		lastPublished(previous, &chart.Metadata{Version: "1.2.7", AppVersion: "1.2.3"}))
+	testza.AssertEqual(t, &StateEntry{Version: "1.2.3", ChartVersion: "1.2.7"},
+		lastPublished(nil, &chart.Metadata{Version: "1.2.7", AppVersion: "1.2.3"}))
+}
@@ -0,0 +1,96 @@
+// Package cache keeps HTTP responses and git reference listings on disk, so
+// repeated runs neither refetch unchanged files nor get rate-limited.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotCached is returned in offline mode for entries missing from the cache.
+var ErrNotCached = errors.New("not cached")
+
+// Cache is a directory of cached entries keyed by URL.
+type Cache struct {
+	dir     string
+	ttl     time.Duration
+	offline bool
+	now     func() time.Time
+}
+
+// New creates a cache in dir. Entries younger than ttl are served without
+// revalidation. In offline mode entries are always served from the cache,
+// regardless of their age, and missing entries fail with ErrNotCached.
+func New(dir string, ttl time.Duration, offline bool) *Cache {
+	return &Cache{
+		dir:     dir,
+		ttl:     ttl,
+		offline: offline,
+		now:     time.Now,
+	}
+}
+
+// fresh reports whether an entry fetched at the given time can be served without revalidation.
+func (c *Cache) fresh(fetched time.Time) bool {
+	return c.offline || c.now().Sub(fetched) < c.ttl
+}
+
+// path returns the file of the entry for key in the given namespace.
+func (c *Cache) path(namespace string, key string, ext string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, namespace, hex.EncodeToString(sum[:])+ext)
+}
+
+// readJSON decodes the file at path into v, reporting false if it does not exist.
+func readJSON(path string, v interface{}) (bool, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "failed reading cache entry")
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return false, fmt.Errorf("failed decoding cache entry %s: %w", path, err)
+	}
+	return true, nil
+}
+
+func writeJSON(path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed encoding cache entry: %w", err)
+	}
+	return writeFile(path, b)
+}
+
+// writeFile replaces the file at path atomically, so concurrent readers never
+// see a partially written entry.
+func writeFile(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "failed creating cache directory")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return errors.Wrap(err, "failed creating cache entry")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "failed writing cache entry")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed writing cache entry")
+	}
+
+	return errors.Wrap(os.Rename(tmp.Name(), path), "failed writing cache entry")
+}
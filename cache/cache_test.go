@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	testza.AssertNoError(t, err)
+	return string(b)
+}
+
+func TestHTTPClient(t *testing.T) {
+	var requests, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer server.Close()
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(t.TempDir(), time.Hour, false)
+	c.now = func() time.Time { return now }
+	client := c.HTTPClient(server.Client())
+
+	resp, err := get(client, server.URL+"/file")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "hello", readBody(t, resp))
+
+	// Fresh entries are served without a request.
+	resp, err = get(client, server.URL+"/file")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "hello", readBody(t, resp))
+	testza.AssertEqual(t, int32(1), atomic.LoadInt32(&requests))
+
+	// Stale entries are revalidated.
+	now = now.Add(2 * time.Hour)
+	resp, err = get(client, server.URL+"/file")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "hello", readBody(t, resp))
+	testza.AssertEqual(t, int32(2), atomic.LoadInt32(&requests))
+	testza.AssertEqual(t, int32(1), atomic.LoadInt32(&notModified))
+
+	// Unsuccessful responses are passed through and not cached.
+	for i := 0; i < 2; i++ {
+		resp, err = get(client, server.URL+"/missing")
+		testza.AssertNoError(t, err)
+		resp.Body.Close()
+		testza.AssertEqual(t, http.StatusNotFound, resp.StatusCode)
+	}
+	testza.AssertEqual(t, int32(4), atomic.LoadInt32(&requests))
+
+	// Offline mode serves stale entries and fails for missing ones.
+	offline := New(c.dir, time.Hour, true)
+	offline.now = func() time.Time { return now.Add(24 * time.Hour) }
+	resp, err = get(offline.HTTPClient(nil), server.URL+"/file")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "hello", readBody(t, resp))
+
+	_, err = get(offline.HTTPClient(nil), server.URL+"/other")
+	testza.AssertTrue(t, errors.Is(err, ErrNotCached))
+	testza.AssertEqual(t, int32(4), atomic.LoadInt32(&requests))
+}
+
+type countingLister struct {
+	calls int
+}
+
+func (l *countingLister) List(_ context.Context, url string) ([]*plumbing.Reference, error) {
+	l.calls++
+	return []*plumbing.Reference{
+		plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/master"),
+		plumbing.NewHashReference("refs/tags/1.0-ls1", plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")),
+	}, nil
+}
+
+func TestRefLister(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(t.TempDir(), time.Hour, false)
+	c.now = func() time.Time { return now }
+	lister := &countingLister{}
+	refs := c.RefLister(lister)
+
+	want, _ := lister.List(context.Background(), "")
+	lister.calls = 0
+
+	for i := 0; i < 2; i++ {
+		got, err := refs.List(context.Background(), "https://github.com/linuxserver/docker-plex")
+		testza.AssertNoError(t, err)
+		testza.AssertEqual(t, want, got)
+	}
+	testza.AssertEqual(t, 1, lister.calls)
+
+	now = now.Add(2 * time.Hour)
+	_, err := refs.List(context.Background(), "https://github.com/linuxserver/docker-plex")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, 2, lister.calls)
+
+	_, err = New(c.dir, time.Hour, true).RefLister(lister).List(context.Background(), "https://github.com/linuxserver/docker-sonarr")
+	testza.AssertTrue(t, errors.Is(err, ErrNotCached))
+	testza.AssertEqual(t, 2, lister.calls)
+}
+
+func get(client *HTTPClient, url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const httpNamespace = "http"
+
+type httpEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	Fetched      time.Time `json:"fetched"`
+}
+
+// HTTPClient serves GET requests from the cache, revalidating stale entries
+// with conditional requests. Only successful responses are cached, other
+// methods are passed through.
+type HTTPClient struct {
+	cache  *Cache
+	client *http.Client
+}
+
+// HTTPClient wraps client with the cache. A nil client uses http.DefaultClient.
+func (c *Cache) HTTPClient(client *http.Client) *HTTPClient {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPClient{cache: c, client: client}
+}
+
+func (h *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return h.client.Do(req)
+	}
+
+	url := req.URL.String()
+	metaPath := h.cache.path(httpNamespace, url, ".json")
+	bodyPath := h.cache.path(httpNamespace, url, ".body")
+
+	var entry httpEntry
+	found, err := readJSON(metaPath, &entry)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if found {
+		body, err = os.ReadFile(bodyPath)
+		if err != nil {
+			found = false
+		}
+	}
+
+	if found && h.cache.fresh(entry.Fetched) {
+		return cachedResponse(body), nil
+	}
+	if h.cache.offline {
+		return nil, fmt.Errorf("%s: %w", url, ErrNotCached)
+	}
+
+	req = req.Clone(req.Context())
+	if found {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed fetching url: "+url)
+	}
+
+	switch {
+	case found && resp.StatusCode == http.StatusNotModified:
+		resp.Body.Close()
+		entry.Fetched = h.cache.now()
+		if err := writeJSON(metaPath, &entry); err != nil {
+			return nil, err
+		}
+		return cachedResponse(body), nil
+	case resp.StatusCode != http.StatusOK:
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading body")
+	}
+
+	if err := writeFile(bodyPath, body); err != nil {
+		return nil, err
+	}
+	entry = httpEntry{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Fetched:      h.cache.now(),
+	}
+	if err := writeJSON(metaPath, &entry); err != nil {
+		return nil, err
+	}
+
+	return cachedResponse(body), nil
+}
+
+func cachedResponse(body []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+}
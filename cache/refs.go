@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+const refsNamespace = "refs"
+
+// Lister lists the references of a remote git repository.
+type Lister interface {
+	List(ctx context.Context, url string) ([]*plumbing.Reference, error)
+}
+
+type refsEntry struct {
+	URL     string      `json:"url"`
+	Fetched time.Time   `json:"fetched"`
+	Refs    [][2]string `json:"refs"`
+}
+
+// RefLister serves reference listings from the cache. Git's smart HTTP
+// protocol has no conditional requests, so stale entries are listed again.
+type RefLister struct {
+	cache  *Cache
+	lister Lister
+}
+
+// RefLister wraps lister with the cache.
+func (c *Cache) RefLister(lister Lister) *RefLister {
+	return &RefLister{cache: c, lister: lister}
+}
+
+func (r *RefLister) List(ctx context.Context, url string) ([]*plumbing.Reference, error) {
+	path := r.cache.path(refsNamespace, url, ".json")
+
+	var entry refsEntry
+	found, err := readJSON(path, &entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if found && r.cache.fresh(entry.Fetched) {
+		refs := make([]*plumbing.Reference, len(entry.Refs))
+		for i, ref := range entry.Refs {
+			refs[i] = plumbing.NewReferenceFromStrings(ref[0], ref[1])
+		}
+		return refs, nil
+	}
+	if r.cache.offline {
+		return nil, fmt.Errorf("%s: %w", url, ErrNotCached)
+	}
+
+	refs, err := r.lister.List(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	entry = refsEntry{
+		URL:     url,
+		Fetched: r.cache.now(),
+		Refs:    make([][2]string, len(refs)),
+	}
+	for i, ref := range refs {
+		s := ref.Strings()
+		entry.Refs[i] = [2]string{s[0], s[1]}
+	}
+	if err := writeJSON(path, &entry); err != nil {
+		return nil, err
+	}
+
+	return refs, nil
+}
@@ -3,8 +3,8 @@ package chart
 import (
 	"bytes"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"path"
 	"strings"
 	"text/template"
 
@@ -12,70 +12,94 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/charrapp/charrapp/parser"
+	charttemplate "github.com/charrapp/charrapp/template"
 )
 
-const (
-	templateDir       = "template"
-	templateExtension = ".gotmpl"
-)
+const templateExtension = ".gotmpl"
 
 type Data struct {
-	Config  *parser.Config
-	Version string
-	Ports   []*ContainerPort
-}
-
-// GenerateChart constructs the chart and returns a map containing all generated files
-func (data *Data) GenerateChart() (map[string][]byte, error) {
-	return data.recursiveGenerateChart(templateDir)
+	Config       *parser.Config
+	Version      string
+	ChartVersion string
+	Tag          string
+	Ports        []*ContainerPort
+	Healthcheck  *Healthcheck
+	Repository   string
+	Deprecated   bool
+	Maintainers  []*Maintainer
+	Project      Project
 }
 
-func (data *Data) recursiveGenerateChart(dirName string) (map[string][]byte, error) {
+// GenerateChart constructs the chart from the template tree in fsys and
+// returns a map containing all generated files keyed by their slash-separated path.
+// A values.schema.json and a README.md are generated unless the template tree provides them.
+// The schema only describes the default values.yaml, so a template tree replacing values.yaml
+// must provide its own schema or the chart is generated without one.
+func (data *Data) GenerateChart(fsys fs.FS) (map[string][]byte, error) {
 	outFiles := make(map[string][]byte)
+	defaultValues := false
 
-	dir, err := os.ReadDir(dirName)
-	if err != nil {
-		return nil, fmt.Errorf("failed reading directory %s: %w", dirName, err)
-	}
-
-	for _, entry := range dir {
-		newPath := filepath.Join(dirName, entry.Name())
+	err := fs.WalkDir(fsys, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed reading %s: %w", filePath, err)
+		}
 		if entry.IsDir() {
-			newFiles, err := data.recursiveGenerateChart(newPath)
-			if err != nil {
-				return nil, err
-			}
+			return nil
+		}
 
-			for p, data := range newFiles {
-				outPath := filepath.Join(newPath, p)
-				outPath = strings.TrimPrefix(outPath, templateDir)
-				outFiles[outPath] = data
-			}
+		file, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return fmt.Errorf("failed reading file %s: %w", filePath, err)
+		}
+
+		if filePath == valuesFile+templateExtension {
+			defaultValues = isDefaultValues(file)
+		}
 
-			continue
+		if path.Ext(filePath) != templateExtension {
+			outFiles[filePath] = file
+			return nil
 		}
 
-		file, err := os.ReadFile(newPath)
+		out, err := data.templateFile(string(file))
+		if err != nil {
+			return fmt.Errorf("failed templating %s: %w", filePath, err)
+		}
+
+		outFiles[strings.TrimSuffix(filePath, templateExtension)] = out
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := outFiles[SchemaFile]; !ok && defaultValues {
+		schema, err := data.GenerateSchema()
 		if err != nil {
-			return nil, fmt.Errorf("failed reading file %s: %w", newPath, err)
+			return nil, err
 		}
+		outFiles[SchemaFile] = schema
+	}
 
-		if filepath.Ext(entry.Name()) == templateExtension {
-			out, err := data.templateFile(string(file))
+	if values, ok := outFiles[valuesFile]; ok {
+		if _, ok := outFiles[ReadmeFile]; !ok {
+			readme, err := data.GenerateReadme(values)
 			if err != nil {
-				return nil, fmt.Errorf("failed templating %s: %w", newPath, err)
+				return nil, err
 			}
-
-			strippedName := entry.Name()[:len(entry.Name())-len(templateExtension)]
-			outFiles[strippedName] = out
-		} else {
-			outFiles[entry.Name()] = file
+			outFiles[ReadmeFile] = readme
 		}
 	}
 
 	return outFiles, nil
 }
 
+// isDefaultValues reports whether file is the embedded values.yaml template.
+func isDefaultValues(file []byte) bool {
+	defaultFile, err := fs.ReadFile(charttemplate.FS, valuesFile+templateExtension)
+	return err == nil && bytes.Equal(file, defaultFile)
+}
+
 func (data *Data) templateFile(file string) ([]byte, error) {
 	funcMap := sprig.HermeticTxtFuncMap()
 	funcMap["toYaml"] = func(v interface{}) string {
@@ -0,0 +1,91 @@
+package chart
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/charrapp/charrapp/parser"
+)
+
+const (
+	hostPathCharDevice = "CharDevice"
+	hostPathDirectory  = "Directory"
+
+	// deviceVolumePrefix is prepended to device names to build their volume
+	// names, which must still fit into a DNS-1123 label.
+	deviceVolumePrefix  = "device-"
+	maxDeviceNameLength = maxVolumeNameLength - len(deviceVolumePrefix)
+)
+
+// deviceDirectories are device paths which are directories of devices rather
+// than a single device, e.g. the render nodes below /dev/dri.
+var deviceDirectories = map[string]bool{
+	"/dev/dri":     true,
+	"/dev/dvb":     true,
+	"/dev/snd":     true,
+	"/dev/input":   true,
+	"/dev/bus/usb": true,
+}
+
+// Device is a host device as it is rendered into the chart devices section.
+type Device struct {
+	Name        string
+	Path        string
+	HostPath    string
+	Type        string
+	Description string
+	Enabled     bool
+}
+
+// ChartDevices returns the devices of the image with unique names, mounted as
+// hostPath volumes. Required devices are enabled by default, optional devices
+// are disabled.
+func (data *Data) ChartDevices() []*Device {
+	devices := make([]*Device, 0, len(data.Config.ParamDevices)+len(data.Config.OptParamDevices))
+	names := make(map[string]bool)
+	seen := make(map[string]bool)
+
+	for i, deviceList := range [][]parser.Device{data.Config.ParamDevices, data.Config.OptParamDevices} {
+		for _, d := range deviceList {
+			if d.DevicePath == "" || seen[d.DevicePath] {
+				continue
+			}
+			seen[d.DevicePath] = true
+
+			hostPath := d.DeviceHostPath
+			if hostPath == "" {
+				hostPath = d.DevicePath
+			}
+
+			name := truncateName(volumeName(d.Name), maxDeviceNameLength)
+			if name == "" {
+				name = truncateName(volumeName(path.Base(d.DevicePath)), maxDeviceNameLength)
+			}
+			if name == "" {
+				name = "device"
+			}
+			for base, n := name, 2; names[name]; n++ {
+				suffix := "-" + strconv.Itoa(n)
+				name = truncateName(base, maxDeviceNameLength-len(suffix)) + suffix
+			}
+			names[name] = true
+
+			typ := hostPathCharDevice
+			if deviceDirectories[path.Clean(hostPath)] {
+				typ = hostPathDirectory
+			}
+
+			devices = append(devices, &Device{
+				Name:        name,
+				Path:        d.DevicePath,
+				HostPath:    hostPath,
+				Type:        typ,
+				Description: strings.Join(strings.Fields(d.Desc), " "),
+				Enabled:     i == 0,
+			})
+		}
+	}
+
+	return devices
+}
@@ -0,0 +1,69 @@
+package chart
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/parser"
+)
+
+func TestChartDevices(t *testing.T) {
+	data := Data{Config: &parser.Config{
+		ParamDevices: []parser.Device{
+			{DevicePath: "/dev/net/tun", Desc: "VPN tunnel"},
+		},
+		OptParamDevices: []parser.Device{
+			{DevicePath: "/dev/dri", DeviceHostPath: "/dev/dri", Desc: "Hardware acceleration"},
+			{DevicePath: "/dev/net/tun"},
+			{DevicePath: "/dev/tun", DeviceHostPath: "/dev/net/tun"},
+		},
+	}}
+
+	testza.AssertEqual(t, []*Device{
+		{Name: "tun", Path: "/dev/net/tun", HostPath: "/dev/net/tun", Type: "CharDevice", Description: "VPN tunnel", Enabled: true},
+		{Name: "dri", Path: "/dev/dri", HostPath: "/dev/dri", Type: "Directory", Description: "Hardware acceleration"},
+		{Name: "tun-2", Path: "/dev/tun", HostPath: "/dev/net/tun", Type: "CharDevice"},
+	}, data.ChartDevices())
+}
+
+func TestChartDevicesLongNames(t *testing.T) {
+	long := "/dev/" + strings.Repeat("a", 70)
+	data := Data{Config: &parser.Config{
+		ParamDevices: []parser.Device{{DevicePath: long}, {DevicePath: long + "/"}},
+	}}
+
+	devices := data.ChartDevices()
+	testza.AssertLen(t, devices, 2)
+	for _, d := range devices {
+		testza.AssertTrue(t, len(deviceVolumePrefix+d.Name) <= maxVolumeNameLength, d.Name)
+	}
+	testza.AssertNotEqual(t, devices[0].Name, devices[1].Name)
+}
+
+func TestChartDevicesValues(t *testing.T) {
+	values := assertValidValues(t, &Data{
+		Config: &parser.Config{
+			ProjectName: "app",
+			OptParamDevices: []parser.Device{
+				{DevicePath: "/dev/dri", DeviceHostPath: "/dev/dri", Desc: "Hardware acceleration"},
+				{DevicePath: "/dev/dvb", DeviceHostPath: "/dev/dvb", Desc: "DVB tuners"},
+			},
+			ReadmeHwaccel: true,
+		},
+		Repository: "lscr.io/linuxserver/app",
+	})
+
+	devices := values["devices"].(map[string]interface{})
+	testza.AssertEqual(t, map[string]interface{}{
+		"enabled":  false,
+		"path":     "/dev/dri",
+		"hostPath": "/dev/dri",
+		"type":     "Directory",
+	}, devices["dri"])
+	testza.AssertLen(t, devices, 2)
+
+	gpu := values["hardware"].(map[string]interface{})["gpu"].(map[string]interface{})
+	testza.AssertEqual(t, false, gpu["enabled"])
+}
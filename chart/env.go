@@ -0,0 +1,78 @@
+package chart
+
+import (
+	"strings"
+
+	"github.com/charrapp/charrapp/parser"
+)
+
+// commonEnvVars are the variables understood by every linuxserver.io base image,
+// enabled through common_param_env_vars_enabled.
+var commonEnvVars = []parser.EnvVar{
+	{EnvVar: "PUID", EnvValue: "1000", Desc: "User ID the app runs as"},
+	{EnvVar: "PGID", EnvValue: "1000", Desc: "Group ID the app runs as"},
+	{EnvVar: "TZ", EnvValue: "Etc/UTC", Desc: "Timezone of the container, see https://en.wikipedia.org/wiki/List_of_tz_database_time_zones#List"},
+}
+
+// EnvVar is a container environment variable as it is rendered into the chart.
+type EnvVar struct {
+	Name        string
+	Value       string
+	Description string
+	Options     []string
+	Enabled     bool
+}
+
+// ChartEnv returns the environment variables of the image. Required variables
+// are enabled by default, optional variables are disabled. Variables listed as
+// required override common variables of the same name.
+func (data *Data) ChartEnv() []*EnvVar {
+	var common []parser.EnvVar
+	if data.Config.CommonParamEnvVarsEnabled {
+		common = commonEnvVars
+	}
+
+	env := make([]*EnvVar, 0, len(common)+len(data.Config.ParamEnvVars)+len(data.Config.OptParamEnvVars))
+	byName := make(map[string]*EnvVar)
+
+	for i, envList := range [][]parser.EnvVar{common, data.Config.ParamEnvVars, data.Config.OptParamEnvVars} {
+		optional := i == 2
+		for _, e := range envList {
+			if e.EnvVar == "" {
+				continue
+			}
+
+			v := &EnvVar{
+				Name:        e.EnvVar,
+				Value:       e.EnvValue,
+				Description: strings.Join(strings.Fields(e.Desc), " "),
+				Options:     e.EnvOptions,
+				Enabled:     !optional,
+			}
+
+			if existing, ok := byName[v.Name]; ok {
+				if !optional {
+					*existing = *v
+				}
+				continue
+			}
+
+			byName[v.Name] = v
+			env = append(env, v)
+		}
+	}
+
+	return env
+}
+
+// EnvEnabled reports whether any environment variable is enabled by default.
+// Otherwise env.vars only holds commented out variables and must be written
+// as an empty mapping.
+func (data *Data) EnvEnabled() bool {
+	for _, e := range data.ChartEnv() {
+		if e.Enabled {
+			return true
+		}
+	}
+	return false
+}
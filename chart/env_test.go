@@ -0,0 +1,33 @@
+package chart
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/parser"
+)
+
+func TestChartEnv(t *testing.T) {
+	data := Data{
+		Config: &parser.Config{
+			CommonParamEnvVarsEnabled: true,
+			ParamEnvVars: []parser.EnvVar{
+				{EnvVar: "TZ", EnvValue: "Europe/London", Desc: "Timezone"},
+				{EnvVar: "MODE", EnvValue: "full", Desc: "Run\n  mode", EnvOptions: []string{"full", "lite"}},
+			},
+			OptParamEnvVars: []parser.EnvVar{
+				{EnvVar: "PUID", EnvValue: "0"},
+				{EnvVar: "TOKEN", Desc: "Claim token"},
+			},
+		},
+	}
+
+	env := data.ChartEnv()
+	testza.AssertLen(t, env, 5)
+
+	testza.AssertEqual(t, &EnvVar{Name: "PUID", Value: "1000", Description: "User ID the app runs as", Enabled: true}, env[0])
+	testza.AssertEqual(t, &EnvVar{Name: "TZ", Value: "Europe/London", Description: "Timezone", Enabled: true}, env[2])
+	testza.AssertEqual(t, &EnvVar{Name: "MODE", Value: "full", Description: "Run mode", Options: []string{"full", "lite"}, Enabled: true}, env[3])
+	testza.AssertEqual(t, &EnvVar{Name: "TOKEN", Description: "Claim token", Enabled: false}, env[4])
+}
@@ -0,0 +1,59 @@
+package chart
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+)
+
+// HashTemplates returns a digest of all file paths and contents in the template tree.
+func HashTemplates(fsys fs.FS) (string, error) {
+	h := sha256.New()
+	err := fs.WalkDir(fsys, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed reading %s: %w", filePath, err)
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		file, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return fmt.Errorf("failed reading file %s: %w", filePath, err)
+		}
+
+		// Length prefixes keep distinct trees from producing the same stream.
+		fmt.Fprintf(h, "%d:%s%d:", len(filePath), filePath, len(file))
+		_, _ = h.Write(file)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// MetadataHash returns a digest of the upstream metadata the chart is generated
+// from: the readme-vars config, the container ports, the health check, the
+// repository, whether the chart is deprecated, its maintainers and the project.
+// The config is hashed as parsed, including its Extras, rather than as the
+// fetched readme-vars bytes, so edits of comments or formatting alone do not
+// regenerate the chart.
+func (data *Data) MetadataHash() (string, error) {
+	h := sha256.New()
+	err := json.NewEncoder(h).Encode(struct {
+		Config      interface{}
+		Ports       []*ContainerPort
+		Healthcheck *Healthcheck
+		Repository  string
+		Deprecated  bool
+		Maintainers []*Maintainer
+		Project     Project
+	}{data.Config, data.Ports, data.Healthcheck, data.Repository, data.Deprecated, data.Maintainers, data.Project})
+	if err != nil {
+		return "", fmt.Errorf("failed hashing metadata: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,145 @@
+package chart
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// IndexFile is the name of the index of a Helm chart repository.
+const IndexFile = "index.yaml"
+
+// ErrVersionExists is returned when a chart version is already published with a different archive.
+var ErrVersionExists = errors.New("chart version already published with a different archive")
+
+// Index is the index.yaml of a Helm chart repository.
+type Index struct {
+	APIVersion string                   `yaml:"apiVersion"`
+	Entries    map[string][]*IndexEntry `yaml:"entries"`
+	Generated  time.Time                `yaml:"generated"`
+}
+
+// IndexEntry is a single chart version listed in a repository index.
+type IndexEntry struct {
+	Metadata `yaml:",inline"`
+	Created  time.Time `yaml:"created"`
+	Digest   string    `yaml:"digest"`
+	URLs     []string  `yaml:"urls"`
+}
+
+// NewIndex creates an empty repository index.
+func NewIndex() *Index {
+	return &Index{
+		APIVersion: "v1",
+		Entries:    make(map[string][]*IndexEntry),
+	}
+}
+
+// LoadIndex parses an existing repository index.
+func LoadIndex(b []byte) (*Index, error) {
+	index := NewIndex()
+	if err := yaml.Unmarshal(b, index); err != nil {
+		return nil, fmt.Errorf("failed parsing %s: %w", IndexFile, err)
+	}
+	if index.Entries == nil {
+		index.Entries = make(map[string][]*IndexEntry)
+	}
+	return index, nil
+}
+
+// Add lists a packaged chart in the index. The archive is expected to be served
+// next to index.yaml, or below baseURL if it is set. Re-adding a listed chart
+// version keeps its creation time and fails with ErrVersionExists unless the
+// archive is unchanged, as published versions must not change.
+func (index *Index) Add(meta *Metadata, archive []byte, baseURL string, created time.Time) error {
+	sum := sha256.Sum256(archive)
+	digest := hex.EncodeToString(sum[:])
+
+	archiveURL := meta.ArchiveName()
+	if baseURL != "" {
+		u, err := url.JoinPath(baseURL, archiveURL)
+		if err != nil {
+			return fmt.Errorf("failed building chart url: %w", err)
+		}
+		archiveURL = u
+	}
+
+	entry := &IndexEntry{
+		Metadata: *meta,
+		Created:  created,
+		Digest:   digest,
+		URLs:     []string{archiveURL},
+	}
+
+	entries := index.Entries[meta.Name]
+	replaced := false
+	for i, existing := range entries {
+		if existing.Version != meta.Version {
+			continue
+		}
+		if existing.Digest != digest {
+			return fmt.Errorf("%s %s: %w", meta.Name, meta.Version, ErrVersionExists)
+		}
+		entry.Created = existing.Created
+		entries[i] = entry
+		replaced = true
+	}
+	if !replaced {
+		entries = append(entries, entry)
+	}
+
+	sortEntries(entries)
+	index.Entries[meta.Name] = entries
+
+	return nil
+}
+
+// Latest returns the highest version of the named chart listed in the index,
+// nil if there is none.
+func (index *Index) Latest(name string) *Metadata {
+	var latest *IndexEntry
+	var latestVersion *semver.Version
+	for _, entry := range index.Entries[name] {
+		v, err := semver.NewVersion(entry.Version)
+		if err != nil {
+			continue
+		}
+		if latestVersion == nil || v.GreaterThan(latestVersion) {
+			latest, latestVersion = entry, v
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	return &latest.Metadata
+}
+
+// Marshal encodes the index, setting its generation time.
+func (index *Index) Marshal(generated time.Time) ([]byte, error) {
+	index.Generated = generated
+	b, err := yaml.Marshal(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding %s: %w", IndexFile, err)
+	}
+	return b, nil
+}
+
+// sortEntries sorts entries from the newest to the oldest version, as Helm does.
+func sortEntries(entries []*IndexEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, errA := semver.NewVersion(entries[i].Version)
+		b, errB := semver.NewVersion(entries[j].Version)
+		if errA != nil || errB != nil {
+			return strings.Compare(entries[i].Version, entries[j].Version) > 0
+		}
+		return a.GreaterThan(b)
+	})
+}
@@ -0,0 +1,142 @@
+package chart
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const changeKindChanged = "changed"
+
+// platforms maps the architectures of readme-vars to OCI platforms.
+var platforms = map[string]string{
+	"x86-64": "linux/amd64",
+	"arm64":  "linux/arm64",
+	"armhf":  "linux/arm/v7",
+}
+
+// Project describes the upstream project of an image beyond its config, as far
+// as the source knows it. Unknown fields are empty.
+type Project struct {
+	License    string
+	SourceURL  string
+	Categories []string
+}
+
+// maintainerRegex matches maintainers written as "Name <email> (url)", where email and url are optional.
+var maintainerRegex = regexp.MustCompile(`^\s*([^<(]*?)\s*(?:<([^>]*)>)?\s*(?:\(([^)]*)\))?\s*$`)
+
+// ParseMaintainer parses a maintainer written as "Name <email> (url)", where email and url are optional.
+func ParseMaintainer(s string) (*Maintainer, error) {
+	match := maintainerRegex.FindStringSubmatch(s)
+	if match == nil || match[1] == "" {
+		return nil, fmt.Errorf("invalid maintainer %q, expected \"Name <email> (url)\"", s)
+	}
+	return &Maintainer{Name: match[1], Email: match[2], URL: match[3]}, nil
+}
+
+// Keywords returns the Chart.yaml keywords: the project name and its categories.
+func (data *Data) Keywords() []string {
+	keywords := []string{strings.ToLower(data.Config.ProjectName)}
+	seen := map[string]bool{keywords[0]: true}
+
+	for _, category := range data.Project.Categories {
+		category = strings.ToLower(category)
+		if !seen[category] {
+			seen[category] = true
+			keywords = append(keywords, category)
+		}
+	}
+	return keywords
+}
+
+// Sources returns the Chart.yaml sources: the project and the repository of the image.
+func (data *Data) Sources() []string {
+	var sources []string
+	if data.Config.ProjectURL != "" {
+		sources = append(sources, data.Config.ProjectURL)
+	}
+	if data.Project.SourceURL != "" {
+		sources = append(sources, data.Project.SourceURL)
+	}
+	return sources
+}
+
+type artifactHubImage struct {
+	Name      string   `yaml:"name"`
+	Image     string   `yaml:"image"`
+	Platforms []string `yaml:"platforms,omitempty"`
+}
+
+type artifactHubChange struct {
+	Kind        string `yaml:"kind"`
+	Description string `yaml:"description"`
+}
+
+type artifactHubLink struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// Annotations returns the Artifact Hub annotations of Chart.yaml.
+func (data *Data) Annotations() (map[string]string, error) {
+	cfg := data.Config
+
+	image := artifactHubImage{Name: cfg.ProjectName, Image: data.Repository}
+	if data.Tag != "" {
+		image.Image += ":" + data.Tag
+	}
+	for _, arch := range cfg.AvailableArchitectures {
+		if platform, ok := platforms[arch.Arch]; ok {
+			image.Platforms = append(image.Platforms, platform)
+		}
+	}
+
+	// The most recent changelog entries share the date of the first one.
+	var changes []artifactHubChange
+	for _, entry := range cfg.Changelogs {
+		if entry.Date != cfg.Changelogs[0].Date {
+			break
+		}
+		changes = append(changes, artifactHubChange{Kind: changeKindChanged, Description: strings.Join(strings.Fields(entry.Desc), " ")})
+	}
+
+	var links []artifactHubLink
+	if cfg.ProjectURL != "" {
+		links = append(links, artifactHubLink{Name: "Upstream project", URL: cfg.ProjectURL})
+	}
+	if data.Project.SourceURL != "" {
+		links = append(links, artifactHubLink{Name: "Image source", URL: data.Project.SourceURL})
+	}
+
+	annotations := make(map[string]string)
+	if data.Project.License != "" {
+		annotations["artifacthub.io/license"] = data.Project.License
+	}
+	if err := setYAMLAnnotation(annotations, "artifacthub.io/images", []artifactHubImage{image}); err != nil {
+		return nil, err
+	}
+	if len(changes) > 0 {
+		if err := setYAMLAnnotation(annotations, "artifacthub.io/changes", changes); err != nil {
+			return nil, err
+		}
+	}
+	if len(links) > 0 {
+		if err := setYAMLAnnotation(annotations, "artifacthub.io/links", links); err != nil {
+			return nil, err
+		}
+	}
+	return annotations, nil
+}
+
+// setYAMLAnnotation sets key to v encoded as YAML, as Artifact Hub expects for structured annotations.
+func setYAMLAnnotation(annotations map[string]string, key string, v interface{}) error {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed encoding %s: %w", key, err)
+	}
+	annotations[key] = string(b)
+	return nil
+}
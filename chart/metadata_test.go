@@ -0,0 +1,73 @@
+package chart
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/parser"
+)
+
+func TestParseMaintainer(t *testing.T) {
+	m, err := ParseMaintainer("Jane Doe <jane@example.com> (https://example.com)")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, &Maintainer{Name: "Jane Doe", Email: "jane@example.com", URL: "https://example.com"}, m)
+
+	m, err = ParseMaintainer("Jane Doe")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, &Maintainer{Name: "Jane Doe"}, m)
+
+	_, err = ParseMaintainer("<jane@example.com>")
+	testza.AssertNotNil(t, err)
+}
+
+func TestKeywords(t *testing.T) {
+	data := Data{
+		Config:  &parser.Config{ProjectName: "Plex"},
+		Project: Project{Categories: []string{"Media Servers", "Music", "plex"}},
+	}
+	testza.AssertEqual(t, []string{"plex", "media servers", "music"}, data.Keywords())
+}
+
+func TestAnnotations(t *testing.T) {
+	data := Data{
+		Config: &parser.Config{
+			ProjectName:            "plex",
+			ProjectURL:             "https://plex.tv",
+			AvailableArchitectures: []parser.Architecture{{Arch: "x86-64"}, {Arch: "armhf"}, {Arch: "riscv"}},
+			Changelogs: []parser.Changelog{
+				{Date: "01.02.23:", Desc: "Add hardware acceleration."},
+				{Date: "01.02.23:", Desc: "Rebase to\nJammy."},
+				{Date: "01.01.23:", Desc: "Older change."},
+			},
+		},
+		Project:    Project{License: "MIT"},
+		Repository: "lscr.io/linuxserver/plex",
+		Tag:        "1.32.5-ls190",
+	}
+	annotations, err := data.Annotations()
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, map[string]string{
+		"artifacthub.io/license": "MIT",
+		"artifacthub.io/images": `- name: plex
+  image: lscr.io/linuxserver/plex:1.32.5-ls190
+  platforms:
+    - linux/amd64
+    - linux/arm/v7
+`,
+		"artifacthub.io/changes": `- kind: changed
+  description: Add hardware acceleration.
+- kind: changed
+  description: Rebase to Jammy.
+`,
+		"artifacthub.io/links": `- name: Upstream project
+  url: https://plex.tv
+`,
+	}, annotations)
+
+	data.Project = Project{}
+	annotations, err = data.Annotations()
+	testza.AssertNoError(t, err)
+	_, ok := annotations["artifacthub.io/license"]
+	testza.AssertFalse(t, ok, "an unknown license must not be annotated")
+}
@@ -0,0 +1,39 @@
+package chart
+
+import "fmt"
+
+const (
+	networkHost   = "host"
+	networkBridge = "bridge"
+)
+
+// HostNetwork reports whether the image is meant to run in the host's network namespace.
+func (data *Data) HostNetwork() bool {
+	return data.Config.ParamUsageIncludeNet && data.Config.ParamNet == networkHost
+}
+
+// Hostname returns the hostname the image is meant to run with as a valid pod
+// hostname, or an empty string if it does not set one.
+func (data *Data) Hostname() string {
+	if !data.Config.ParamUsageIncludeHostname {
+		return ""
+	}
+	return volumeName(data.Config.ParamHostname)
+}
+
+// Warnings lists the parameters of the image which the chart cannot honour.
+func (data *Data) Warnings() []string {
+	var warnings []string
+	cfg := data.Config
+
+	if cfg.ParamUsageIncludeMacAddress && cfg.ParamMacAddress != "" {
+		warnings = append(warnings, fmt.Sprintf("param_mac_address %s is ignored, Kubernetes cannot assign a MAC address to a pod", cfg.ParamMacAddress))
+	}
+	if cfg.ParamUsageIncludeNet && cfg.ParamNet != "" && cfg.ParamNet != networkHost && cfg.ParamNet != networkBridge {
+		warnings = append(warnings, fmt.Sprintf("param_net %s is ignored, only host networking is supported", cfg.ParamNet))
+	}
+	if hostname := data.Hostname(); cfg.ParamUsageIncludeHostname && hostname != cfg.ParamHostname {
+		warnings = append(warnings, fmt.Sprintf("param_hostname %q is not a valid pod hostname, using %q", cfg.ParamHostname, hostname))
+	}
+	return append(warnings, data.securityWarnings()...)
+}
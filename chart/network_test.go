@@ -0,0 +1,36 @@
+package chart
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/parser"
+)
+
+func TestNetwork(t *testing.T) {
+	data := Data{Config: &parser.Config{
+		ParamUsageIncludeNet:        true,
+		ParamNet:                    "host",
+		ParamUsageIncludeHostname:   true,
+		ParamHostname:               "plex",
+		ParamUsageIncludeMacAddress: true,
+		ParamMacAddress:             "12:34:56:78:9a:bc",
+	}}
+
+	testza.AssertTrue(t, data.HostNetwork())
+	testza.AssertEqual(t, "plex", data.Hostname())
+	testza.AssertEqual(t, []string{
+		"param_mac_address 12:34:56:78:9a:bc is ignored, Kubernetes cannot assign a MAC address to a pod",
+	}, data.Warnings())
+
+	data.Config.ParamNet = "vpn"
+	data.Config.ParamHostname = "My_Server"
+	data.Config.ParamUsageIncludeMacAddress = false
+	testza.AssertFalse(t, data.HostNetwork())
+	testza.AssertEqual(t, "my-server", data.Hostname())
+	testza.AssertEqual(t, []string{
+		"param_net vpn is ignored, only host networking is supported",
+		`param_hostname "My_Server" is not a valid pod hostname, using "my-server"`,
+	}, data.Warnings())
+}
@@ -0,0 +1,25 @@
+package chart
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/parser"
+	charttemplate "github.com/charrapp/charrapp/template"
+)
+
+func TestNotesDeprecation(t *testing.T) {
+	data := Data{Config: &parser.Config{ProjectName: "plex"}}
+	files, err := data.GenerateChart(charttemplate.FS)
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, strings.HasPrefix(string(files["templates/NOTES.txt"]), `{{- $fullName`))
+
+	data.Deprecated = true
+	data.Config.ProjectDeprecationMessage = "Use {{ other }} instead.\n"
+	files, err = data.GenerateChart(charttemplate.FS)
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, strings.HasPrefix(string(files["templates/NOTES.txt"]),
+		"WARNING: plex is deprecated upstream and no longer receives updates.\n{{ \"Use {{ other }} instead.\" }}\n{{- \"\\n\" }}\n{{- $fullName"))
+}
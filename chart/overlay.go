@@ -0,0 +1,89 @@
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	charttemplate "github.com/charrapp/charrapp/template"
+)
+
+// Templates returns the chart template tree. The embedded default templates
+// are used as-is if overlayDir is empty, otherwise files in overlayDir replace
+// or add to the defaults.
+func Templates(overlayDir string) (fs.FS, error) {
+	if overlayDir == "" {
+		return charttemplate.FS, nil
+	}
+
+	info, err := os.Stat(overlayDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening template overlay: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("template overlay %s is not a directory", overlayDir)
+	}
+
+	return Overlay(os.DirFS(overlayDir), charttemplate.FS), nil
+}
+
+// Overlay returns a file system in which files of upper shadow files of lower
+// generating the same chart file. Directories present in both are merged.
+func Overlay(upper fs.FS, lower fs.FS) fs.FS {
+	return &overlayFS{upper: upper, lower: lower}
+}
+
+type overlayFS struct {
+	upper fs.FS
+	lower fs.FS
+}
+
+func (o *overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.upper.Open(name)
+	if err == nil {
+		return f, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return o.lower.Open(name)
+}
+
+func (o *overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	upper, upperErr := fs.ReadDir(o.upper, name)
+	if upperErr != nil && !errors.Is(upperErr, fs.ErrNotExist) {
+		return nil, upperErr
+	}
+
+	lower, lowerErr := fs.ReadDir(o.lower, name)
+	if lowerErr != nil && !errors.Is(lowerErr, fs.ErrNotExist) {
+		return nil, lowerErr
+	}
+
+	if upperErr != nil && lowerErr != nil {
+		return nil, upperErr
+	}
+
+	// Entries are keyed by the name of the file they generate, so a plain
+	// file in upper also shadows a template rendering to the same name in lower.
+	entries := make(map[string]fs.DirEntry, len(upper)+len(lower))
+	for _, entry := range lower {
+		entries[strings.TrimSuffix(entry.Name(), templateExtension)] = entry
+	}
+	for _, entry := range upper {
+		entries[strings.TrimSuffix(entry.Name(), templateExtension)] = entry
+	}
+
+	out := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name() < out[j].Name()
+	})
+
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package chart
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/parser"
+	charttemplate "github.com/charrapp/charrapp/template"
+)
+
+func TestOverlay(t *testing.T) {
+	lower := fstest.MapFS{
+		"Chart.yaml.gotmpl":      {Data: []byte("name: {{ .Config.ProjectName }}")},
+		"values.yaml.gotmpl":     {Data: []byte("generated: true")},
+		"templates/service.yaml": {Data: []byte("default service")},
+	}
+	upper := fstest.MapFS{
+		"values.yaml":            {Data: []byte("custom: true")},
+		"templates/service.yaml": {Data: []byte("custom service")},
+		"templates/extra.yaml":   {Data: []byte("extra")},
+		"values.schema.json":     {Data: []byte("{}")},
+		"README.md":              {Data: []byte("# plex")},
+		"notes.txt.gotmpl":       {Data: []byte("{{ .Config.Extras.project_lsio_github_repo_url }}")},
+	}
+
+	data := Data{Config: &parser.Config{
+		ProjectName: "plex",
+		Extras:      map[string]interface{}{"project_lsio_github_repo_url": "https://github.com/linuxserver/docker-plex"},
+	}}
+	files, err := data.GenerateChart(Overlay(upper, lower))
+	testza.AssertNoError(t, err)
+
+	testza.AssertEqual(t, map[string][]byte{
+		"Chart.yaml":             []byte("name: plex"),
+		"values.yaml":            []byte("custom: true"),
+		"templates/service.yaml": []byte("custom service"),
+		"templates/extra.yaml":   []byte("extra"),
+		"values.schema.json":     []byte("{}"),
+		"README.md":              []byte("# plex"),
+		"notes.txt":              []byte("https://github.com/linuxserver/docker-plex"),
+	}, files)
+}
+
+func TestOverlayValuesWithoutSchema(t *testing.T) {
+	upper := fstest.MapFS{
+		"values.yaml": {Data: []byte("custom: true")},
+	}
+
+	data := Data{Config: &parser.Config{ProjectName: "plex"}}
+	files, err := data.GenerateChart(Overlay(upper, charttemplate.FS))
+	testza.AssertNoError(t, err)
+
+	_, ok := files[SchemaFile]
+	testza.AssertFalse(t, ok, "the generated schema would reject the custom values")
+	testza.AssertEqual(t, []byte("custom: true"), files["values.yaml"])
+}
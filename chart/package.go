@@ -0,0 +1,120 @@
+package chart
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	chartFile   = "Chart.yaml"
+	archiveMode = 0o644
+	archiveExt  = ".tgz"
+)
+
+// archiveModTime is the modification time of all archive entries.
+var archiveModTime = time.Unix(0, 0).UTC()
+
+// Maintainer is a maintainer of a chart as listed in Chart.yaml.
+type Maintainer struct {
+	Name  string `yaml:"name"`
+	Email string `yaml:"email,omitempty"`
+	URL   string `yaml:"url,omitempty"`
+}
+
+// Metadata is the content of a Chart.yaml file.
+type Metadata struct {
+	APIVersion  string            `yaml:"apiVersion"`
+	Name        string            `yaml:"name"`
+	Version     string            `yaml:"version"`
+	AppVersion  string            `yaml:"appVersion,omitempty"`
+	KubeVersion string            `yaml:"kubeVersion,omitempty"`
+	Description string            `yaml:"description,omitempty"`
+	Type        string            `yaml:"type,omitempty"`
+	Keywords    []string          `yaml:"keywords,omitempty"`
+	Home        string            `yaml:"home,omitempty"`
+	Sources     []string          `yaml:"sources,omitempty"`
+	Maintainers []*Maintainer     `yaml:"maintainers,omitempty"`
+	Icon        string            `yaml:"icon,omitempty"`
+	Deprecated  bool              `yaml:"deprecated,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// ParseMetadata reads the Chart.yaml of the generated chart files.
+func ParseMetadata(files map[string][]byte) (*Metadata, error) {
+	b, ok := files[chartFile]
+	if !ok {
+		return nil, fmt.Errorf("chart does not contain %s", chartFile)
+	}
+
+	meta := &Metadata{}
+	if err := yaml.Unmarshal(b, meta); err != nil {
+		return nil, fmt.Errorf("failed parsing %s: %w", chartFile, err)
+	}
+
+	if meta.Name == "" || meta.Version == "" {
+		return nil, fmt.Errorf("%s must contain a name and a version", chartFile)
+	}
+
+	return meta, nil
+}
+
+// ArchiveName returns the file name Helm uses for the archive of the chart.
+func (meta *Metadata) ArchiveName() string {
+	return meta.Name + "-" + meta.Version + archiveExt
+}
+
+// Package writes the generated chart files into a Helm chart archive. As with
+// helm package, all files are stored below a directory named after the chart
+// and Chart.yaml is the first entry of the archive. All entries share a fixed
+// modification time, so the same files always give the same archive.
+func Package(files map[string][]byte) (*Metadata, []byte, error) {
+	meta, err := ParseMetadata(files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if name != chartFile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{chartFile}, names...)
+
+	var out bytes.Buffer
+	gz := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range names {
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     path.Join(meta.Name, name),
+			Mode:     archiveMode,
+			Size:     int64(len(files[name])),
+			ModTime:  archiveModTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, nil, fmt.Errorf("failed writing archive header for %s: %w", name, err)
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return nil, nil, fmt.Errorf("failed writing %s to archive: %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed closing archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed compressing archive: %w", err)
+	}
+
+	return meta, out.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package chart
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestPackage(t *testing.T) {
+	files := map[string][]byte{
+		"values.yaml":            []byte("replicaCount: 1\n"),
+		"templates/service.yaml": []byte("kind: Service\n"),
+		"Chart.yaml":             []byte("apiVersion: v2\nname: plex\nversion: 1.2.3\nappVersion: 1.2.3\n"),
+	}
+
+	meta, archive, err := Package(files)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "plex-1.2.3.tgz", meta.ArchiveName())
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	testza.AssertNoError(t, err)
+	tr := tar.NewReader(gz)
+
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		testza.AssertNoError(t, err)
+		names = append(names, header.Name)
+	}
+
+	testza.AssertEqual(t, []string{"plex/Chart.yaml", "plex/templates/service.yaml", "plex/values.yaml"}, names)
+
+	_, again, err := Package(files)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, archive, again)
+}
+
+func TestIndex(t *testing.T) {
+	created := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	index := NewIndex()
+
+	for _, version := range []string{"1.0.0", "1.10.0", "1.2.0"} {
+		meta := &Metadata{APIVersion: "v2", Name: "plex", Version: version}
+		testza.AssertNoError(t, index.Add(meta, []byte(version), "https://charts.example.com/", created))
+	}
+
+	// Re-adding an unchanged archive keeps the original creation time.
+	meta := &Metadata{APIVersion: "v2", Name: "plex", Version: "1.2.0"}
+	testza.AssertNoError(t, index.Add(meta, []byte("1.2.0"), "https://charts.example.com/", created.Add(time.Hour)))
+
+	entries := index.Entries["plex"]
+	testza.AssertLen(t, entries, 3)
+	testza.AssertEqual(t, "1.10.0", entries[0].Version)
+	testza.AssertEqual(t, "1.2.0", entries[1].Version)
+	testza.AssertEqual(t, created, entries[1].Created)
+	testza.AssertEqual(t, []string{"https://charts.example.com/plex-1.2.0.tgz"}, entries[1].URLs)
+	testza.AssertEqual(t, "1.10.0", index.Latest("plex").Version)
+	testza.AssertNil(t, index.Latest("sonarr"))
+
+	// A published version cannot be replaced by a different archive.
+	err := index.Add(meta, []byte("changed"), "https://charts.example.com/", created)
+	testza.AssertTrue(t, errors.Is(err, ErrVersionExists))
+	testza.AssertEqual(t, entries[1].Digest, index.Entries["plex"][1].Digest)
+
+	b, err := index.Marshal(created)
+	testza.AssertNoError(t, err)
+
+	loaded, err := LoadIndex(b)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, index.Entries["plex"][2].Digest, loaded.Entries["plex"][2].Digest)
+}
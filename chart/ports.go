@@ -0,0 +1,119 @@
+package chart
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	protocolTCP = "TCP"
+	protocolUDP = "UDP"
+
+	// maxPortNameLength is the maximum length of a Kubernetes port name (IANA_SVC_NAME).
+	maxPortNameLength = 15
+
+	httpsPort = 443
+)
+
+var (
+	nameInvalidRegex    = regexp.MustCompile(`[^a-z0-9]+`)
+	portNameLetterRegex = regexp.MustCompile(`[a-z]`)
+	webPortRegex        = regexp.MustCompile(`(?i)web\s*-?ui|web\s*interface|\bhttps?\b`)
+	httpsRegex          = regexp.MustCompile(`(?i)\bhttps\b`)
+)
+
+// Port is a container port as it is rendered into the chart.
+type Port struct {
+	Name        string
+	Number      uint16
+	Protocol    string
+	Description string
+}
+
+// Protocol returns the Kubernetes protocol of the port.
+func (p *ContainerPort) Protocol() string {
+	if p.TCP {
+		return protocolTCP
+	}
+	return protocolUDP
+}
+
+// ChartPorts returns the discovered ports with unique Kubernetes port names.
+// Ports listed more than once with the same protocol are only returned once.
+func (data *Data) ChartPorts() []*Port {
+	ports := make([]*Port, 0, len(data.Ports))
+	names := make(map[string]bool)
+	seen := make(map[string]bool)
+
+	for _, p := range data.Ports {
+		fallback := fmt.Sprintf("%s-%d", strings.ToLower(p.Protocol()), p.Number)
+		if seen[fallback] {
+			continue
+		}
+		seen[fallback] = true
+
+		name := portName(p.Name)
+		if name == "" {
+			name = portName(p.Description)
+		}
+		if name == "" || names[name] {
+			name = fallback
+		}
+		for base, n := name, 2; names[name]; n++ {
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+		names[name] = true
+
+		ports = append(ports, &Port{
+			Name:        name,
+			Number:      p.Number,
+			Protocol:    p.Protocol(),
+			Description: strings.Join(strings.Fields(p.Description), " "),
+		})
+	}
+
+	return ports
+}
+
+// WebPort returns the port serving the web UI: the first TCP port whose name or
+// description mentions a web UI or HTTP, or else the first TCP port. It returns
+// nil if the app has no TCP ports.
+func (data *Data) WebPort() *Port {
+	port, _ := data.webPort()
+	return port
+}
+
+// HTTPS reports whether the port serves HTTPS rather than plain HTTP.
+func (p *Port) HTTPS() bool {
+	return p.Number == httpsPort || httpsRegex.MatchString(p.Name) || httpsRegex.MatchString(p.Description)
+}
+
+// webPort returns the port WebPort chooses and whether it was identified as
+// serving HTTP rather than picked as the first TCP port.
+func (data *Data) webPort() (*Port, bool) {
+	var first *Port
+	for _, p := range data.ChartPorts() {
+		if p.Protocol != protocolTCP {
+			continue
+		}
+		if webPortRegex.MatchString(p.Name) || webPortRegex.MatchString(p.Description) {
+			return p, true
+		}
+		if first == nil {
+			first = p
+		}
+	}
+	return first, false
+}
+
+// portName converts s into a valid Kubernetes port name, returning an empty
+// string if s cannot be used as one without losing its meaning.
+func portName(s string) string {
+	name := nameInvalidRegex.ReplaceAllString(strings.ToLower(s), "-")
+	name = strings.Trim(name, "-")
+	if len(name) > maxPortNameLength || !portNameLetterRegex.MatchString(name) {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,56 @@
+package chart
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestChartPorts(t *testing.T) {
+	data := Data{
+		Ports: []*ContainerPort{
+			{Number: 32400, TCP: true, Description: "WebUI"},
+			{Number: 1900, TCP: false},
+			{Number: 1900, TCP: false},
+			{Number: 3005, TCP: true, Name: "plex companion"},
+			{Number: 8324, TCP: true, Description: "Roku via Plex Companion"},
+			{Number: 8080, TCP: true, Name: "webui"},
+		},
+	}
+
+	ports := data.ChartPorts()
+	testza.AssertLen(t, ports, 5)
+
+	names := make([]string, len(ports))
+	for i, p := range ports {
+		names[i] = p.Name
+	}
+	testza.AssertEqual(t, []string{"webui", "udp-1900", "plex-companion", "tcp-8324", "tcp-8080"}, names)
+	testza.AssertEqual(t, "UDP", ports[1].Protocol)
+
+	// A fallback name taken by a derived name gets a suffix.
+	data.Ports = []*ContainerPort{
+		{Number: 80, TCP: true, Description: "tcp 81"},
+		{Number: 81, TCP: true},
+	}
+	ports = data.ChartPorts()
+	testza.AssertEqual(t, "tcp-81", ports[0].Name)
+	testza.AssertEqual(t, "tcp-81-2", ports[1].Name)
+}
+
+func TestWebPort(t *testing.T) {
+	data := Data{
+		Ports: []*ContainerPort{
+			{Number: 51820, TCP: false, Description: "WireGuard HTTP"},
+			{Number: 8443, TCP: true, Description: "Internal API"},
+			{Number: 8080, TCP: true, Description: "Web UI"},
+		},
+	}
+	testza.AssertEqual(t, "web-ui", data.WebPort().Name)
+
+	data.Ports = data.Ports[:2]
+	testza.AssertEqual(t, uint16(8443), data.WebPort().Number)
+
+	data.Ports = data.Ports[:1]
+	testza.AssertNil(t, data.WebPort())
+}
@@ -0,0 +1,83 @@
+package chart
+
+import "time"
+
+// Docker's defaults for HEALTHCHECK options, used when an option is not set.
+const (
+	defaultHealthcheckInterval = 30 * time.Second
+	defaultHealthcheckTimeout  = 30 * time.Second
+	defaultHealthcheckRetries  = 3
+)
+
+const schemeHTTPS = "HTTPS"
+
+// Probe is a Kubernetes container probe as it is rendered into the values.
+type Probe struct {
+	Exec                *ExecAction      `yaml:"exec,omitempty"`
+	HTTPGet             *HTTPGetAction   `yaml:"httpGet,omitempty"`
+	TCPSocket           *TCPSocketAction `yaml:"tcpSocket,omitempty"`
+	InitialDelaySeconds int              `yaml:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int              `yaml:"periodSeconds,omitempty"`
+	TimeoutSeconds      int              `yaml:"timeoutSeconds,omitempty"`
+	FailureThreshold    int              `yaml:"failureThreshold,omitempty"`
+}
+
+type ExecAction struct {
+	Command []string `yaml:"command"`
+}
+
+type HTTPGetAction struct {
+	Path   string `yaml:"path"`
+	Port   string `yaml:"port"`
+	Scheme string `yaml:"scheme,omitempty"`
+}
+
+type TCPSocketAction struct {
+	Port string `yaml:"port"`
+}
+
+// ChartProbe returns the probe used for liveness and readiness of the
+// container. The image's HEALTHCHECK is preferred, then an HTTP probe on an
+// identified web port, using HTTPS on 443 or ports described as HTTPS, then
+// a TCP probe on the first TCP port. It returns nil
+// if the app only has UDP ports, as those cannot be probed.
+func (data *Data) ChartProbe() *Probe {
+	if h := data.Healthcheck; h != nil {
+		probe := &Probe{
+			Exec:                &ExecAction{Command: h.Command},
+			InitialDelaySeconds: int(h.StartPeriod.Seconds()),
+			PeriodSeconds:       seconds(h.Interval, defaultHealthcheckInterval),
+			TimeoutSeconds:      seconds(h.Timeout, defaultHealthcheckTimeout),
+			FailureThreshold:    h.Retries,
+		}
+		if probe.FailureThreshold == 0 {
+			probe.FailureThreshold = defaultHealthcheckRetries
+		}
+		return probe
+	}
+
+	port, http := data.webPort()
+	switch {
+	case port == nil:
+		return nil
+	case http:
+		action := &HTTPGetAction{Path: "/", Port: port.Name}
+		if port.HTTPS() {
+			action.Scheme = schemeHTTPS
+		}
+		return &Probe{HTTPGet: action}
+	default:
+		return &Probe{TCPSocket: &TCPSocketAction{Port: port.Name}}
+	}
+}
+
+// seconds converts d to whole seconds of at least one, using fallback if d is not set.
+func seconds(d time.Duration, fallback time.Duration) int {
+	if d <= 0 {
+		d = fallback
+	}
+	if d < time.Second {
+		return 1
+	}
+	return int(d.Seconds())
+}
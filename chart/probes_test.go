@@ -0,0 +1,37 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestChartProbe(t *testing.T) {
+	data := Data{Ports: []*ContainerPort{
+		{Number: 51820, TCP: false},
+		{Number: 8443, TCP: true, Description: "API"},
+		{Number: 8080, TCP: true, Description: "WebUI"},
+	}}
+	testza.AssertEqual(t, &Probe{HTTPGet: &HTTPGetAction{Path: "/", Port: "webui"}}, data.ChartProbe())
+
+	data.Ports[2].Description = "WebUI (HTTPS)"
+	testza.AssertEqual(t, &Probe{HTTPGet: &HTTPGetAction{Path: "/", Port: "webui-https", Scheme: "HTTPS"}}, data.ChartProbe())
+
+	data.Ports[2] = &ContainerPort{Number: 443, TCP: true, Description: "WebUI"}
+	testza.AssertEqual(t, &Probe{HTTPGet: &HTTPGetAction{Path: "/", Port: "webui", Scheme: "HTTPS"}}, data.ChartProbe())
+
+	data.Ports = data.Ports[:2]
+	testza.AssertEqual(t, &Probe{TCPSocket: &TCPSocketAction{Port: "api"}}, data.ChartProbe())
+
+	data.Ports = data.Ports[:1]
+	testza.AssertNil(t, data.ChartProbe())
+
+	data.Healthcheck = &Healthcheck{Command: []string{"/healthcheck"}, Timeout: 5 * time.Second}
+	testza.AssertEqual(t, &Probe{
+		Exec:             &ExecAction{Command: []string{"/healthcheck"}},
+		PeriodSeconds:    30,
+		TimeoutSeconds:   5,
+		FailureThreshold: 3,
+	}, data.ChartProbe())
+}
@@ -0,0 +1,213 @@
+package chart
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ReadmeFile is the name of the generated chart documentation.
+	ReadmeFile = "README.md"
+
+	valuesFile = "values.yaml"
+
+	// maxChangelogEntries is the number of most recent changelog entries in the README.
+	maxChangelogEntries = 10
+)
+
+var (
+	// valueDocRegex matches the "# key -- description" comments of values.yaml.
+	valueDocRegex = regexp.MustCompile(`^\s*# ([A-Za-z0-9_.\-/]+) -- (.*)$`)
+	// commentRegex matches continuation lines of a value description.
+	commentRegex = regexp.MustCompile(`^\s*# ?(.*)$`)
+	// commentedYAMLRegex matches commented out YAML, which is not part of a description.
+	commentedYAMLRegex = regexp.MustCompile(`^\s*#\s*(- )?[A-Za-z0-9_.\-/]+:`)
+)
+
+// ValueDoc documents a single key of values.yaml.
+type ValueDoc struct {
+	Key         string
+	Type        string
+	Default     string
+	Description string
+}
+
+// ValueDocs collects the keys documented with "# key -- description" comments
+// in values, in the order they appear. Objects whose keys are documented
+// themselves have no default, keys missing from values have type "unset".
+func ValueDocs(values []byte) ([]*ValueDoc, error) {
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(values, &tree); err != nil {
+		return nil, fmt.Errorf("failed parsing %s: %w", valuesFile, err)
+	}
+
+	var docs []*ValueDoc
+	var current *ValueDoc
+	scanner := bufio.NewScanner(bytes.NewReader(values))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := valueDocRegex.FindStringSubmatch(line); match != nil {
+			current = &ValueDoc{Key: match[1], Description: strings.TrimSpace(match[2])}
+			docs = append(docs, current)
+			continue
+		}
+		if match := commentRegex.FindStringSubmatch(line); match != nil && current != nil && !commentedYAMLRegex.MatchString(line) {
+			current.Description = strings.TrimSpace(current.Description + " " + match[1])
+			continue
+		}
+		current = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading %s: %w", valuesFile, err)
+	}
+
+	documented := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		documented[doc.Key] = true
+	}
+
+	for _, doc := range docs {
+		value, ok := lookupValue(tree, doc.Key)
+		if !ok {
+			doc.Type = "unset"
+			continue
+		}
+		doc.Type = valueType(value)
+		if hasDocumentedChildren(doc.Key, documented) {
+			continue
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed encoding default of %s: %w", doc.Key, err)
+		}
+		doc.Default = string(b)
+	}
+	return docs, nil
+}
+
+func lookupValue(tree map[string]interface{}, key string) (interface{}, bool) {
+	var value interface{} = tree
+	for _, part := range strings.Split(key, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func hasDocumentedChildren(key string, documented map[string]bool) bool {
+	for other := range documented {
+		if strings.HasPrefix(other, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func valueType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case int:
+		return "int"
+	case float64:
+		return "float"
+	case bool:
+		return "bool"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "null"
+	}
+}
+
+// GenerateReadme renders the chart documentation from the metadata of the
+// app and the documented keys of the rendered values.
+func (data *Data) GenerateReadme(values []byte) ([]byte, error) {
+	docs, err := ValueDocs(values)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := data.Config
+	name := cfg.ProjectName
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", name)
+	fmt.Fprintf(&b, "![Version: %[1]s](https://img.shields.io/badge/Version-%[2]s-informational?style=flat-square) ", data.ChartVersion, badgeEscape(data.ChartVersion))
+	fmt.Fprintf(&b, "![AppVersion: %[1]s](https://img.shields.io/badge/AppVersion-%[2]s-informational?style=flat-square)\n\n", data.Version, badgeEscape(data.Version))
+	if cfg.ProjectBlurb != "" {
+		fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(cfg.ProjectBlurb))
+	}
+	if cfg.ProjectURL != "" {
+		fmt.Fprintf(&b, "**Homepage:** <%s>\n\n", cfg.ProjectURL)
+	}
+	if data.Deprecated {
+		message := strings.Join(strings.Fields(cfg.ProjectDeprecationMessage), " ")
+		if message == "" {
+			message = "The image is deprecated upstream."
+		}
+		fmt.Fprintf(&b, "> **Deprecated:** %s\n\n", message)
+	}
+
+	b.WriteString("## Installing the chart\n\n")
+	b.WriteString("With the chart repository added as `charrapp`, install the chart with the release name `" + name + "`:\n\n")
+	fmt.Fprintf(&b, "```console\nhelm install %[1]s charrapp/%[1]s\n```\n\n", name)
+	fmt.Fprintf(&b, "The image `%s` is used, which is available for:\n\n", data.Repository)
+	if len(cfg.AvailableArchitectures) > 0 {
+		b.WriteString("| Architecture | Tag |\n| --- | --- |\n")
+		for _, arch := range cfg.AvailableArchitectures {
+			fmt.Fprintf(&b, "| %s | %s |\n", MarkdownCell(arch.Arch), MarkdownCell(arch.Tag))
+		}
+		b.WriteString("\n")
+	}
+
+	if cfg.AppSetupBlockEnabled && strings.TrimSpace(cfg.AppSetupBlock) != "" {
+		fmt.Fprintf(&b, "## Application setup\n\n%s\n\n", strings.TrimSpace(cfg.AppSetupBlock))
+	}
+
+	b.WriteString("## Values\n\n| Key | Type | Default | Description |\n| --- | --- | --- | --- |\n")
+	for _, doc := range docs {
+		def := ""
+		if doc.Default != "" {
+			def = "`" + MarkdownCell(doc.Default) + "`"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", MarkdownCell(doc.Key), doc.Type, def, MarkdownCell(doc.Description))
+	}
+
+	if len(cfg.Changelogs) > 0 {
+		b.WriteString("\n## Changelog\n\n")
+		for i, entry := range cfg.Changelogs {
+			if i == maxChangelogEntries {
+				break
+			}
+			fmt.Fprintf(&b, "- **%s** %s\n", strings.TrimSuffix(strings.TrimSpace(entry.Date), ":"), strings.Join(strings.Fields(entry.Desc), " "))
+		}
+	}
+
+	return b.Bytes(), nil
+}
+
+// badgeEscape escapes s for use in a shields.io badge path.
+func badgeEscape(s string) string {
+	return strings.NewReplacer("-", "--", "_", "__", " ", "_").Replace(s)
+}
+
+// MarkdownCell escapes s for a Markdown table cell, collapsing all whitespace
+// so it stays on a single row.
+func MarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
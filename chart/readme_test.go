@@ -0,0 +1,62 @@
+package chart
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/parser"
+)
+
+const documentedValues = `# replicaCount -- Replica count
+replicaCount: 1
+
+service:
+    # service.type -- Service type,
+    # one of ClusterIP or NodePort
+    type: ClusterIP
+
+# tls -- TLS configuration
+tls: []
+# - secretName: example
+
+env:
+    # env.vars -- Environment variables
+    vars:
+        # env.vars.TZ -- Timezone
+        TZ: "Etc/UTC"
+        # env.vars.CLAIM -- Claim token
+        # CLAIM: ""
+`
+
+func TestValueDocs(t *testing.T) {
+	docs, err := ValueDocs([]byte(documentedValues))
+	testza.AssertNoError(t, err)
+
+	testza.AssertEqual(t, []*ValueDoc{
+		{Key: "replicaCount", Type: "int", Default: "1", Description: "Replica count"},
+		{Key: "service.type", Type: "string", Default: `"ClusterIP"`, Description: "Service type, one of ClusterIP or NodePort"},
+		{Key: "tls", Type: "list", Default: "[]", Description: "TLS configuration"},
+		{Key: "env.vars", Type: "object", Description: "Environment variables"},
+		{Key: "env.vars.TZ", Type: "string", Default: `"Etc/UTC"`, Description: "Timezone"},
+		{Key: "env.vars.CLAIM", Type: "unset", Description: "Claim token"},
+	}, docs)
+}
+
+func TestGenerateReadme(t *testing.T) {
+	data := Data{
+		Config: &parser.Config{
+			ProjectName: "plex",
+			Changelogs:  []parser.Changelog{{Date: "01.02.23:", Desc: "Initial release"}},
+		},
+		Version:      "1.32.5",
+		ChartVersion: "1.32.6",
+		Deprecated:   true,
+	}
+
+	readme, err := data.GenerateReadme([]byte(documentedValues))
+	testza.AssertNoError(t, err)
+	testza.AssertContains(t, string(readme), "> **Deprecated:** The image is deprecated upstream.")
+	testza.AssertContains(t, string(readme), "| service.type | string | `\"ClusterIP\"` | Service type, one of ClusterIP or NodePort |")
+	testza.AssertContains(t, string(readme), "- **01.02.23** Initial release")
+}
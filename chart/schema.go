@@ -0,0 +1,285 @@
+package chart
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// SchemaFile is the name of the JSON schema Helm validates values against.
+	SchemaFile = "values.schema.json"
+
+	schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+	maxPort = 65535
+)
+
+// Schema is a JSON schema (draft 7) node.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// GenerateSchema returns the values.schema.json describing every key of the
+// generated values.yaml, so Helm rejects mistyped overrides.
+func (data *Data) GenerateSchema() ([]byte, error) {
+	schema := data.valuesSchema()
+	schema.Schema = schemaDraft
+
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding %s: %w", SchemaFile, err)
+	}
+	return append(b, '\n'), nil
+}
+
+func (data *Data) valuesSchema() *Schema {
+	return object(map[string]*Schema{
+		"global":           {Type: "object"},
+		"replicaCount":     integer(0, -1, "Replica count of the deployment"),
+		"image":            imageSchema(),
+		"imagePullSecrets": array(freeObject(""), "List of secrets for images"),
+		"nameOverride":     str("Name override for all resources"),
+		"fullnameOverride": str("Full name override for all resources"),
+		"serviceAccount": object(map[string]*Schema{
+			"create":      boolean("Specifies whether a service account should be created"),
+			"annotations": stringMap("Annotations to add to the service account"),
+			"name":        str("The name of the service account to use"),
+		}, ""),
+		"podAnnotations":     stringMap("Any extra annotations for all pods"),
+		"podSecurityContext": freeObject("Security context override for all pods"),
+		"securityContext":    freeObject("Security context of the container"),
+		"hostNetwork":        boolean("Run the pod in the host's network namespace"),
+		"dnsPolicy":          enum("DNS policy of the pod", "", "ClusterFirst", "ClusterFirstWithHostNet", "Default", "None"),
+		"hostname":           str("Hostname of the pod"),
+		"service": object(map[string]*Schema{
+			"type": enum("Service type to be used", "ClusterIP", "NodePort", "LoadBalancer"),
+		}, ""),
+		"ingress":        ingressSchema(),
+		"httpRoute":      httpRouteSchema(),
+		"resources":      freeObject("Any resource configuration applied to all pods"),
+		"persistence":    data.persistenceSchema(),
+		"devices":        data.devicesSchema(),
+		"hardware":       data.hardwareSchema(),
+		"ports":          array(portSchema(), "List of ports exposed by the container and the service"),
+		"livenessProbe":  freeObject("Liveness probe of the container, an empty object disables it"),
+		"readinessProbe": freeObject("Readiness probe of the container, an empty object disables it"),
+		"autoscaling": object(map[string]*Schema{
+			"enabled":                           boolean("Enable HPA"),
+			"minReplicas":                       integer(1, -1, "Min amount of replicas for HPA"),
+			"maxReplicas":                       integer(1, -1, "Max amount of replicas for HPA"),
+			"targetCPUUtilizationPercentage":    integer(1, 100, "Target CPU usage for HPA"),
+			"targetMemoryUtilizationPercentage": integer(1, 100, "Target memory usage for HPA"),
+		}, ""),
+		"nodeSelector": stringMap("Specify the nodeSelector for all pods"),
+		"tolerations":  array(freeObject(""), "Specify the tolerations for all pods"),
+		"affinity":     freeObject("Specify the affinity for all pods"),
+		"command":      array(str(""), "Override command for all pods"),
+		"args":         array(str(""), "Override arguments for all pods"),
+		"env":          data.envSchema(),
+	}, "")
+}
+
+func imageSchema() *Schema {
+	return object(map[string]*Schema{
+		"repository": str("Image to be used for deployment"),
+		"pullPolicy": enum("Pull policy of the deployment", "Always", "IfNotPresent", "Never"),
+		"tag":        str("Image tag"),
+	}, "")
+}
+
+// portNameSchema matches Kubernetes port names (IANA_SVC_NAME): lowercase
+// alphanumerics and dashes containing at least one letter, starting and ending
+// with an alphanumeric.
+func portNameSchema() *Schema {
+	maxLength := maxPortNameLength
+	return &Schema{
+		Type:        "string",
+		Pattern:     "^([a-z]|[0-9][a-z0-9-]*[a-z])([a-z0-9-]*[a-z0-9])?$",
+		MaxLength:   &maxLength,
+		Description: "Name of the port",
+	}
+}
+
+func portSchema() *Schema {
+	port := object(map[string]*Schema{
+		"name":     portNameSchema(),
+		"port":     integer(1, maxPort, "Port number"),
+		"protocol": enum("Protocol of the port", protocolTCP, protocolUDP, "SCTP"),
+	}, "")
+	port.Required = []string{"name", "port"}
+	return port
+}
+
+func ingressSchema() *Schema {
+	path := object(map[string]*Schema{
+		"path":     str("Path routed to the web UI"),
+		"pathType": enum("How the path is matched", "Prefix", "Exact", "ImplementationSpecific"),
+	}, "")
+	host := object(map[string]*Schema{
+		"host":  str("Host routed to the web UI"),
+		"paths": array(path, ""),
+	}, "")
+	tls := object(map[string]*Schema{
+		"secretName": str("Secret holding the certificate"),
+		"hosts":      array(str(""), "Hosts covered by the certificate"),
+	}, "")
+
+	return object(map[string]*Schema{
+		"enabled":     boolean("Expose the web UI through an Ingress"),
+		"className":   str("IngressClass of the Ingress"),
+		"annotations": stringMap("Annotations to add to the Ingress"),
+		"servicePort": str("Name of the entry in ports the Ingress routes to"),
+		"hosts":       array(host, "Hosts and paths routed to the web UI"),
+		"tls":         array(tls, "TLS configuration of the Ingress"),
+	}, "")
+}
+
+func httpRouteSchema() *Schema {
+	return object(map[string]*Schema{
+		"enabled":     boolean("Expose the web UI through a Gateway API HTTPRoute"),
+		"annotations": stringMap("Annotations to add to the HTTPRoute"),
+		"parentRefs":  array(freeObject(""), "Gateways the HTTPRoute attaches to"),
+		"hostnames":   array(str(""), "Hostnames matched by the HTTPRoute"),
+		"servicePort": str("Name of the entry in ports the HTTPRoute routes to"),
+		"matches":     array(freeObject(""), "Requests routed to the web UI"),
+	}, "")
+}
+
+func volumeSchema(description string) *Schema {
+	return object(map[string]*Schema{
+		"enabled":       boolean("Mount the volume into the container"),
+		"type":          enum("Kind of volume backing the mount", "pvc", "hostPath", "emptyDir"),
+		"mountPath":     str("Path the volume is mounted at"),
+		"existingClaim": str("Use an existing PersistentVolumeClaim instead of creating one"),
+		"storageClass":  str("Storage class of the created PersistentVolumeClaim"),
+		"size":          str("Size of the created PersistentVolumeClaim"),
+		"accessMode":    enum("Access mode of the created PersistentVolumeClaim", "ReadWriteOnce", "ReadOnlyMany", "ReadWriteMany", "ReadWriteOncePod"),
+		"hostPath":      str("Path on the node for volumes of type hostPath"),
+	}, description)
+}
+
+func (data *Data) persistenceSchema() *Schema {
+	properties := make(map[string]*Schema)
+	for _, v := range data.ChartVolumes() {
+		properties[v.Name] = volumeSchema(v.Description)
+	}
+
+	schema := object(properties, "Volumes of the app")
+	schema.AdditionalProperties = volumeSchema("")
+	return schema
+}
+
+func deviceSchema(description string) *Schema {
+	return object(map[string]*Schema{
+		"enabled":  boolean("Pass the device into the container"),
+		"path":     str("Path of the device in the container"),
+		"hostPath": str("Path of the device on the node"),
+		"type":     enum("hostPath type of the device", hostPathCharDevice, hostPathDirectory, "BlockDevice"),
+	}, description)
+}
+
+func (data *Data) devicesSchema() *Schema {
+	properties := make(map[string]*Schema)
+	for _, d := range data.ChartDevices() {
+		properties[d.Name] = deviceSchema(d.Description)
+	}
+
+	schema := object(properties, "Host devices passed into the container")
+	schema.AdditionalProperties = deviceSchema("")
+	return schema
+}
+
+func (data *Data) hardwareSchema() *Schema {
+	properties := map[string]*Schema{
+		"privileged":         boolean("Run the container privileged"),
+		"supplementalGroups": array(integer(0, -1, ""), "Extra groups of the pod"),
+	}
+	if data.Config.ReadmeHwaccel {
+		properties["gpu"] = object(map[string]*Schema{
+			"enabled":  boolean("Request a GPU from a device plugin for hardware acceleration"),
+			"resource": str("Extended resource of the device plugin"),
+			"count":    integer(1, -1, "Number of GPUs requested"),
+		}, "")
+	}
+	return object(properties, "")
+}
+
+func (data *Data) envSchema() *Schema {
+	properties := make(map[string]*Schema)
+	for _, e := range data.ChartEnv() {
+		if len(e.Options) == 0 {
+			properties[e.Name] = envValue(e.Description)
+			continue
+		}
+
+		values := make([]interface{}, 0, len(e.Options)+1)
+		for _, option := range e.Options {
+			values = append(values, option)
+		}
+		values = append(values, nil)
+		properties[e.Name] = &Schema{Description: e.Description, Enum: values}
+	}
+
+	vars := object(properties, "Environment variables of all pods")
+	vars.AdditionalProperties = envValue("")
+
+	return object(map[string]*Schema{
+		"vars":   vars,
+		"extras": array(freeObject(""), "Any extra environment variables appended to all pods"),
+	}, "")
+}
+
+// object is a mapping which only allows the given properties.
+func object(properties map[string]*Schema, description string) *Schema {
+	return &Schema{Type: "object", Description: description, Properties: properties, AdditionalProperties: false}
+}
+
+// freeObject is a mapping passed through to Kubernetes as-is.
+func freeObject(description string) *Schema {
+	return &Schema{Type: "object", Description: description}
+}
+
+func stringMap(description string) *Schema {
+	return &Schema{Type: "object", Description: description, AdditionalProperties: &Schema{Type: "string"}}
+}
+
+func array(items *Schema, description string) *Schema {
+	return &Schema{Type: "array", Description: description, Items: items}
+}
+
+func str(description string) *Schema {
+	return &Schema{Type: "string", Description: description}
+}
+
+func boolean(description string) *Schema {
+	return &Schema{Type: "boolean", Description: description}
+}
+
+// integer is an integer within [lower, upper], a negative upper bound leaves it unbounded.
+func integer(lower int, upper int, description string) *Schema {
+	s := &Schema{Type: "integer", Description: description, Minimum: &lower}
+	if upper >= 0 {
+		s.Maximum = &upper
+	}
+	return s
+}
+
+func enum(description string, values ...interface{}) *Schema {
+	return &Schema{Type: "string", Description: description, Enum: values}
+}
+
+// envValue accepts any scalar, as values are converted to strings by the templates.
+func envValue(description string) *Schema {
+	return &Schema{Type: []string{"string", "number", "boolean", "null"}, Description: description}
+}
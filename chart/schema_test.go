@@ -0,0 +1,92 @@
+package chart
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+
+	"github.com/charrapp/charrapp/parser"
+	charttemplate "github.com/charrapp/charrapp/template"
+)
+
+// assertValidValues generates the chart of data and validates its values.yaml against its schema.
+func assertValidValues(t *testing.T, data *Data) map[string]interface{} {
+	t.Helper()
+
+	files, err := data.GenerateChart(charttemplate.FS)
+	testza.AssertNoError(t, err)
+	if err != nil {
+		return nil
+	}
+
+	var values map[string]interface{}
+	testza.AssertNoError(t, yaml.Unmarshal(files[valuesFile], &values))
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(files[SchemaFile]), gojsonschema.NewGoLoader(values))
+	testza.AssertNoError(t, err)
+	if err == nil {
+		testza.AssertTrue(t, result.Valid(), result.Errors())
+	}
+	return values
+}
+
+func TestSchemaOptionalEnvOnly(t *testing.T) {
+	values := assertValidValues(t, &Data{
+		Config: &parser.Config{
+			ProjectName:     "app",
+			OptParamEnvVars: []parser.EnvVar{{EnvVar: "TOKEN", Desc: "Claim token"}},
+		},
+		Repository: "lscr.io/linuxserver/app",
+	})
+	testza.AssertEqual(t, map[string]interface{}{}, values["env"].(map[string]interface{})["vars"])
+}
+
+func TestSchemaMultilineVolumeDescription(t *testing.T) {
+	assertValidValues(t, &Data{
+		Config: &parser.Config{
+			ProjectName:  "app",
+			ParamVolumes: []parser.Volume{{VolPath: "/config", Desc: "Configs\nand: logs"}},
+		},
+		Repository: "lscr.io/linuxserver/app",
+	})
+}
+
+func TestSchemaMultilinePortDescription(t *testing.T) {
+	assertValidValues(t, &Data{
+		Config:     &parser.Config{ProjectName: "app"},
+		Ports:      []*ContainerPort{{Number: 8080, TCP: true, Description: "Web UI\nport: http"}},
+		Repository: "lscr.io/linuxserver/app",
+	})
+}
+
+func TestPortNameSchema(t *testing.T) {
+	schema, err := json.Marshal(portNameSchema())
+	testza.AssertNoError(t, err)
+
+	for name, valid := range map[string]bool{
+		"webui":            true,
+		"tcp-81":           true,
+		"1a":               true,
+		"81":               false,
+		"-web":             false,
+		"web-":             false,
+		"a-very-long-name": false,
+	} {
+		result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewGoLoader(name))
+		testza.AssertNoError(t, err)
+		testza.AssertEqual(t, valid, result.Valid(), name)
+	}
+}
+
+func TestSchemaMultilineDeviceDescription(t *testing.T) {
+	assertValidValues(t, &Data{
+		Config: &parser.Config{
+			ProjectName:  "app",
+			ParamDevices: []parser.Device{{DevicePath: "/dev/dri", Desc: "Hardware\nacceleration: Intel"}},
+		},
+		Repository: "lscr.io/linuxserver/app",
+	})
+}
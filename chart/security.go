@@ -0,0 +1,134 @@
+package chart
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charrapp/charrapp/parser"
+)
+
+const (
+	profileUnconfined = "Unconfined"
+
+	// appArmorAnnotationPrefix is followed by the container name. The
+	// appArmorProfile field of the security context needs Kubernetes 1.30.
+	appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+)
+
+// SecurityContext is a Kubernetes container security context as it is rendered into the values.
+type SecurityContext struct {
+	Capabilities             *Capabilities    `yaml:"capabilities,omitempty"`
+	AllowPrivilegeEscalation *bool            `yaml:"allowPrivilegeEscalation,omitempty"`
+	SeccompProfile           *SecurityProfile `yaml:"seccompProfile,omitempty"`
+}
+
+type Capabilities struct {
+	Add []string `yaml:"add,omitempty"`
+}
+
+// SecurityProfile is a seccomp profile.
+type SecurityProfile struct {
+	Type string `yaml:"type"`
+}
+
+// ChartSecurityContext translates the capabilities and security options the
+// image requires into a container security context, nil if it requires none.
+// linuxserver.io images start as root and drop privileges to PUID/PGID in
+// s6-overlay, so the context never enforces a user or a read-only filesystem.
+func (data *Data) ChartSecurityContext() *SecurityContext {
+	ctx := &SecurityContext{}
+
+	if caps := capabilities(data.Config.CapAddParamVars); len(caps) > 0 {
+		ctx.Capabilities = &Capabilities{Add: caps}
+	}
+
+	for _, opt := range data.Config.SecurityOptParamVars {
+		applySecurityOpt(ctx, opt)
+	}
+
+	// Images flagged with readme_seccomp use syscalls blocked by the default
+	// seccomp profile of older hosts.
+	if data.Config.ReadmeSeccomp && ctx.SeccompProfile == nil {
+		ctx.SeccompProfile = &SecurityProfile{Type: profileUnconfined}
+	}
+
+	if *ctx == (SecurityContext{}) {
+		return nil
+	}
+	return ctx
+}
+
+// OptionalCapabilities returns the capabilities needed only for optional features of the image.
+func (data *Data) OptionalCapabilities() []string {
+	return capabilities(data.Config.OptCapAddParamVars)
+}
+
+// capabilities returns the unique Kubernetes capability names of vars.
+func capabilities(vars []parser.CapAddVar) []string {
+	var caps []string
+	seen := make(map[string]bool)
+	for _, v := range vars {
+		name := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(v.CapAddVar)), "CAP_")
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		caps = append(caps, name)
+	}
+	return caps
+}
+
+// PodAnnotations returns the annotations of the pod, which select the
+// AppArmor profile of the container if the image requires one.
+func (data *Data) PodAnnotations() map[string]string {
+	for _, opt := range data.Config.SecurityOptParamVars {
+		if key, value := securityOpt(opt); key == "apparmor" && value != "" {
+			if value != "unconfined" {
+				value = "localhost/" + value
+			}
+			return map[string]string{appArmorAnnotationPrefix + data.Config.ProjectName: value}
+		}
+	}
+	return nil
+}
+
+// securityWarnings lists the security options of the image which the chart cannot honour.
+func (data *Data) securityWarnings() []string {
+	var warnings []string
+	for _, opt := range data.Config.SecurityOptParamVars {
+		if key, value := securityOpt(opt); key == "seccomp" && value != "unconfined" {
+			warnings = append(warnings, fmt.Sprintf("security_opt seccomp=%s is ignored, Kubernetes only loads seccomp profiles from the kubelet's seccomp directory", value))
+		}
+	}
+	return warnings
+}
+
+// securityOpt splits a docker --security-opt, e.g. "seccomp=unconfined", into its key and value.
+func securityOpt(opt parser.SecurityOptVar) (key, value string) {
+	s := opt.RunVar
+	if s == "" {
+		s = opt.ComposeVar
+	}
+	key, value, ok := strings.Cut(s, "=")
+	if !ok {
+		key, value, _ = strings.Cut(s, ":")
+	}
+	return strings.TrimSpace(key), strings.TrimSpace(value)
+}
+
+// applySecurityOpt applies a docker --security-opt to ctx. Options without a
+// Kubernetes security context equivalent are ignored; a seccomp profile on the
+// docker host cannot be referenced from a pod.
+func applySecurityOpt(ctx *SecurityContext, opt parser.SecurityOptVar) {
+	switch key, value := securityOpt(opt); key {
+	case "seccomp":
+		if value == "unconfined" {
+			ctx.SeccompProfile = &SecurityProfile{Type: profileUnconfined}
+		}
+	case "no-new-privileges":
+		if value == "" || value == "true" {
+			disallow := false
+			ctx.AllowPrivilegeEscalation = &disallow
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package chart
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/parser"
+)
+
+func TestChartSecurityContext(t *testing.T) {
+	data := Data{Config: &parser.Config{}}
+	testza.AssertNil(t, data.ChartSecurityContext())
+
+	data.Config.ReadmeSeccomp = true
+	testza.AssertEqual(t, &SecurityContext{SeccompProfile: &SecurityProfile{Type: "Unconfined"}}, data.ChartSecurityContext())
+
+	data.Config.CapAddParamVars = []parser.CapAddVar{{CapAddVar: "NET_ADMIN"}, {CapAddVar: "cap_sys_module"}, {CapAddVar: "NET_ADMIN"}}
+	data.Config.OptCapAddParamVars = []parser.CapAddVar{{CapAddVar: "SYS_ADMIN"}}
+	data.Config.SecurityOptParamVars = []parser.SecurityOptVar{
+		{RunVar: "seccomp=/path/to/profile.json"},
+		{ComposeVar: "apparmor:unconfined"},
+		{RunVar: "no-new-privileges"},
+		{RunVar: "label=disable"},
+	}
+
+	disallow := false
+	testza.AssertEqual(t, &SecurityContext{
+		Capabilities:             &Capabilities{Add: []string{"NET_ADMIN", "SYS_MODULE"}},
+		AllowPrivilegeEscalation: &disallow,
+		SeccompProfile:           &SecurityProfile{Type: "Unconfined"},
+	}, data.ChartSecurityContext())
+	testza.AssertEqual(t, []string{"SYS_ADMIN"}, data.OptionalCapabilities())
+	testza.AssertLen(t, data.Warnings(), 1)
+
+	data.Config.ReadmeSeccomp = false
+	testza.AssertNil(t, data.ChartSecurityContext().SeccompProfile)
+}
+
+func TestPodAnnotations(t *testing.T) {
+	data := Data{Config: &parser.Config{ProjectName: "app"}}
+	testza.AssertNil(t, data.PodAnnotations())
+
+	data.Config.SecurityOptParamVars = []parser.SecurityOptVar{{ComposeVar: "apparmor:unconfined"}}
+	testza.AssertEqual(t, map[string]string{"container.apparmor.security.beta.kubernetes.io/app": "unconfined"}, data.PodAnnotations())
+
+	data.Config.SecurityOptParamVars = []parser.SecurityOptVar{{RunVar: "apparmor=docker-app"}}
+	testza.AssertEqual(t, map[string]string{"container.apparmor.security.beta.kubernetes.io/app": "localhost/docker-app"}, data.PodAnnotations())
+
+	values := assertValidValues(t, &Data{Config: data.Config, Repository: "lscr.io/linuxserver/app"})
+	testza.AssertEqual(t, map[string]interface{}{"container.apparmor.security.beta.kubernetes.io/app": "localhost/docker-app"}, values["podAnnotations"])
+}
@@ -2,13 +2,26 @@ package chart
 
 import (
 	"sort"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 )
 
 type ContainerPort struct {
-	Number uint16
-	TCP    bool
+	Number      uint16
+	TCP         bool
+	Name        string
+	Description string
+}
+
+// Healthcheck is the HEALTHCHECK instruction of an image. Zero durations and
+// retries mean the Docker defaults apply.
+type Healthcheck struct {
+	Command     []string
+	Interval    time.Duration
+	Timeout     time.Duration
+	StartPeriod time.Duration
+	Retries     int
 }
 
 type Version struct {
@@ -0,0 +1,91 @@
+package chart
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charrapp/charrapp/parser"
+)
+
+const (
+	// maxVolumeNameLength is the maximum length of a Kubernetes volume name (DNS-1123 label).
+	maxVolumeNameLength = 63
+
+	volumeTypePVC      = "pvc"
+	volumeTypeHostPath = "hostPath"
+
+	// placeholderHostPath prefixes the example host paths of readme-vars,
+	// which are left to the user, unlike system paths such as /lib/modules.
+	placeholderHostPath = "/path/to/"
+)
+
+// Volume is a container volume as it is rendered into the chart persistence section.
+type Volume struct {
+	Name        string
+	MountPath   string
+	Type        string
+	HostPath    string
+	Description string
+	Enabled     bool
+}
+
+// ChartVolumes returns the volumes of the image with unique Kubernetes volume names.
+// Required volumes are enabled by default, optional volumes are disabled.
+// Volumes of a system path on the host default to a hostPath volume of that path.
+func (data *Data) ChartVolumes() []*Volume {
+	volumes := make([]*Volume, 0, len(data.Config.ParamVolumes)+len(data.Config.OptParamVolumes))
+	names := make(map[string]bool)
+	seen := make(map[string]bool)
+
+	for i, volumeList := range [][]parser.Volume{data.Config.ParamVolumes, data.Config.OptParamVolumes} {
+		for _, v := range volumeList {
+			if v.VolPath == "" || seen[v.VolPath] {
+				continue
+			}
+			seen[v.VolPath] = true
+
+			name := volumeName(v.Name)
+			if name == "" {
+				name = volumeName(v.VolPath)
+			}
+			if name == "" {
+				name = "root"
+			}
+			for base, n := name, 2; names[name]; n++ {
+				name = base + "-" + strconv.Itoa(n)
+			}
+			names[name] = true
+
+			volumeType, hostPath := volumeTypePVC, ""
+			if v.VolHostPath != "" && !strings.HasPrefix(v.VolHostPath, placeholderHostPath) {
+				volumeType, hostPath = volumeTypeHostPath, v.VolHostPath
+			}
+
+			volumes = append(volumes, &Volume{
+				Name:        name,
+				MountPath:   v.VolPath,
+				Type:        volumeType,
+				HostPath:    hostPath,
+				Description: strings.Join(strings.Fields(v.Desc), " "),
+				Enabled:     i == 0,
+			})
+		}
+	}
+
+	return volumes
+}
+
+// volumeName converts s into a valid Kubernetes volume name.
+func volumeName(s string) string {
+	name := nameInvalidRegex.ReplaceAllString(strings.ToLower(s), "-")
+	name = strings.Trim(name, "-")
+	return truncateName(name, maxVolumeNameLength)
+}
+
+// truncateName shortens name to at most maxLength characters, not ending in a dash.
+func truncateName(name string, maxLength int) string {
+	if len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], "-")
+	}
+	return name
+}
@@ -0,0 +1,33 @@
+package chart
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/parser"
+)
+
+func TestChartVolumes(t *testing.T) {
+	data := Data{
+		Config: &parser.Config{
+			ParamVolumes: []parser.Volume{
+				{VolPath: "/config", VolHostPath: "/path/to/appdata/config", Desc: "Configuration files."},
+				{VolPath: "/data/media"},
+				{VolPath: "/lib/modules", VolHostPath: "/lib/modules"},
+				{VolPath: "/config"},
+			},
+			OptParamVolumes: []parser.Volume{
+				{VolPath: "/backups", Name: "config"},
+			},
+		},
+	}
+
+	volumes := data.ChartVolumes()
+	testza.AssertLen(t, volumes, 4)
+
+	testza.AssertEqual(t, &Volume{Name: "config", MountPath: "/config", Type: "pvc", Description: "Configuration files.", Enabled: true}, volumes[0])
+	testza.AssertEqual(t, "data-media", volumes[1].Name)
+	testza.AssertEqual(t, &Volume{Name: "lib-modules", MountPath: "/lib/modules", Type: "hostPath", HostPath: "/lib/modules", Enabled: true}, volumes[2])
+	testza.AssertEqual(t, &Volume{Name: "config-2", MountPath: "/backups", Type: "pvc", Enabled: false}, volumes[3])
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/charrapp/charrapp/bulk"
+	"github.com/charrapp/charrapp/chart"
+)
+
+const defaultOutput = "out"
+
+func runGenerate(cmd *command, args []string) int {
+	flags := cmd.flagSet()
+	srcOpts := sourceFlags(flags)
+	output := flags.String("output", defaultOutput, "directory the charts are written to")
+	all := flags.Bool("all", false, "generate charts for all available images")
+	overlay := flags.String("templates", "", "directory whose files replace or add to the default chart templates")
+	pkg := flags.Bool("package", false, "write charts as .tgz archives and maintain a repository index.yaml")
+	repoURL := flags.String("url", "", "base URL the packaged charts are served from, used in index.yaml")
+	flags.BoolVar(&srcOpts.strict, "strict", false, "reject readme-vars files with unknown keys, type mismatches or inconsistent toggles")
+	workers := flags.Int("workers", bulk.DefaultWorkers, "number of images processed concurrently")
+	reportPath := flags.String("report", "", "write a summary of the run to this file, as Markdown if it ends in .md and JSON otherwise")
+	statePath := flags.String("state", "", "file recording the inputs of the generated charts (default <output>/"+bulk.StateFile+")")
+	force := flags.Bool("force", false, "regenerate charts even if their inputs did not change")
+	deprecation := flags.String("deprecated", string(bulk.DeprecationFlag), "how to chart images deprecated upstream: skip them, flag the charts as deprecated or ignore the deprecation")
+	var maintainers maintainerFlag
+	flags.Var(&maintainers, "maintainer", "maintainer listed in every Chart.yaml as \"Name <email> (url)\", may be repeated")
+	bump := flags.String("bump", "", "rules mapping the changed part of the app version to the part of the chart version to bump (default \"major=major,minor=minor,patch=patch\")")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	if *all && flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "charrapp: --all cannot be combined with image names")
+		return exitUsage
+	}
+	if !*all && flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	policy, err := bulk.ParseVersionPolicy(*bump)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "charrapp: %s\n", err)
+		return exitUsage
+	}
+	deprecationPolicy, err := bulk.ParseDeprecationPolicy(*deprecation)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "charrapp: %s\n", err)
+		return exitUsage
+	}
+
+	src, err := openSource(srcOpts)
+	if err != nil {
+		return fail(err)
+	}
+
+	templates, err := chart.Templates(*overlay)
+	if err != nil {
+		return fail(err)
+	}
+
+	if *statePath == "" {
+		*statePath = filepath.Join(*output, bulk.StateFile)
+	}
+	state, err := loadState(*statePath)
+	if err != nil {
+		return fail(err)
+	}
+
+	var writer chartWriter = &dirWriter{output: *output}
+	if *pkg {
+		writer, err = newPackageWriter(*output, *repoURL)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	images := flags.Args()
+	if *all {
+		images, err = src.Images(ctx)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	report := bulk.Run(ctx, src, images, bulk.Options{
+		Workers:       *workers,
+		Templates:     templates,
+		Write:         writer.Write,
+		Published:     writer.Published,
+		State:         state,
+		Force:         *force,
+		VersionPolicy: &policy,
+		Deprecation:   deprecationPolicy,
+		Maintainers:   maintainers,
+		Prune:         *all,
+		Progress: func(result *bulk.Result) {
+			if result.Reason != "" {
+				fmt.Fprintf(os.Stderr, "%s: %s: %s\n", result.Image, result.Status, result.Reason)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", result.Image, result.Status)
+			}
+			for _, warning := range result.Warnings {
+				fmt.Fprintf(os.Stderr, "%s: warning: %s\n", result.Image, warning)
+			}
+		},
+	})
+
+	if err := writer.Close(); err != nil {
+		return fail(err)
+	}
+	if err := saveState(state, *statePath); err != nil {
+		return fail(err)
+	}
+
+	if *reportPath != "" {
+		if err := writeReport(report, *reportPath); err != nil {
+			return fail(err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "charrapp: %s\n", report.Summary())
+	if report.Count(bulk.StatusFailed) > 0 || ctx.Err() != nil {
+		return exitError
+	}
+	return exitOK
+}
+
+// writeReport writes the run summary as Markdown if path ends in .md and as JSON otherwise.
+func writeReport(report *bulk.Report, path string) error {
+	var b []byte
+	if strings.EqualFold(filepath.Ext(path), ".md") {
+		b = report.Markdown()
+	} else {
+		var err error
+		b, err = report.JSON()
+		if err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(os.WriteFile(path, b, 0o644), "failed writing report")
+}
+
+// loadState reads the state file at path, returning an empty state if it does not exist yet.
+func loadState(path string) (*bulk.State, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return bulk.NewState(), nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading state")
+	}
+	return bulk.LoadState(b)
+}
+
+func saveState(state *bulk.State, path string) error {
+	b, err := state.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "failed creating directory")
+	}
+	return errors.Wrap(os.WriteFile(path, b, 0o644), "failed writing state")
+}
+
+// chartWriter stores generated charts.
+type chartWriter interface {
+	Write(image string, files map[string][]byte) error
+	Published(name string) *chart.Metadata
+	Close() error
+}
+
+// dirWriter writes every chart into a directory named after its image.
+type dirWriter struct {
+	output string
+}
+
+func (w *dirWriter) Write(image string, files map[string][]byte) error {
+	return writeFiles(filepath.Join(w.output, image), files)
+}
+
+func (w *dirWriter) Published(string) *chart.Metadata {
+	return nil
+}
+
+func (w *dirWriter) Close() error {
+	return nil
+}
+
+func writeFiles(dir string, files map[string][]byte) error {
+	for name, b := range files {
+		realPath := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(realPath), 0o755); err != nil {
+			return errors.Wrap(err, "failed creating directory")
+		}
+		if err := os.WriteFile(realPath, b, 0o644); err != nil {
+			return errors.Wrap(err, "failed writing file: "+realPath)
+		}
+	}
+	return nil
+}
+
+// maintainerFlag collects the repeated --maintainer flag.
+type maintainerFlag []*chart.Maintainer
+
+func (f *maintainerFlag) String() string {
+	names := make([]string, 0, len(*f))
+	for _, m := range *f {
+		names = append(names, m.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (f *maintainerFlag) Set(s string) error {
+	m, err := chart.ParseMaintainer(s)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, m)
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func runList(cmd *command, args []string) int {
+	flags := cmd.flagSet()
+	srcOpts := sourceFlags(flags)
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	src, err := openSource(srcOpts)
+	if err != nil {
+		return fail(err)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	images, err := src.Images(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, image := range images {
+		fmt.Fprintln(os.Stdout, image)
+	}
+	return exitOK
+}
@@ -0,0 +1,118 @@
+// Command charrapp generates Helm charts for container images.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(cmd *command, args []string) int
+}
+
+var commands = []*command{
+	{
+		name:    "generate",
+		usage:   "generate [source flags] [--strict] [--output dir] [--templates dir] [--package [--url url]] [--workers n] [--report file] [--state file] [--force] [--bump rules] [--deprecated skip|flag|ignore] [--maintainer maintainer]... (--all | <image>...)",
+		summary: "generate charts for the given images",
+		run:     runGenerate,
+	},
+	{
+		name:    "list",
+		usage:   "list [source flags]",
+		summary: "list all available images",
+		run:     runList,
+	},
+	{
+		name:    "versions",
+		usage:   "versions [source flags] <image>",
+		summary: "list the versions of an image",
+		run:     runVersions,
+	},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitUsage
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(cmd, args[1:])
+		}
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage()
+		return exitOK
+	}
+
+	fmt.Fprintf(os.Stderr, "charrapp: unknown command %q\n", args[0])
+	usage()
+	return exitUsage
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: charrapp <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+func (cmd *command) flagSet() *flag.FlagSet {
+	flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: charrapp %s\n", cmd.usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags parses args into flags and reports the exit code to use if
+// parsing did not succeed.
+func parseFlags(flags *flag.FlagSet, args []string) (int, bool) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+func fail(err error) int {
+	fmt.Fprintf(os.Stderr, "charrapp: %s\n", err)
+	return exitError
+}
+
+// interruptContext returns a context canceled by the first SIGINT or SIGTERM.
+// Later signals are no longer caught, so they terminate the process.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
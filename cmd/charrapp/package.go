@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/charrapp/charrapp/chart"
+)
+
+// packageWriter writes every chart as an archive into a directory which can be
+// served as a Helm chart repository.
+type packageWriter struct {
+	output  string
+	url     string
+	index   *chart.Index
+	created time.Time
+}
+
+func newPackageWriter(output string, url string) (*packageWriter, error) {
+	index := chart.NewIndex()
+
+	b, err := os.ReadFile(filepath.Join(output, chart.IndexFile))
+	switch {
+	case err == nil:
+		index, err = chart.LoadIndex(b)
+		if err != nil {
+			return nil, err
+		}
+	case !os.IsNotExist(err):
+		return nil, errors.Wrap(err, "failed reading repository index")
+	}
+
+	return &packageWriter{
+		output:  output,
+		url:     url,
+		index:   index,
+		created: time.Now().UTC(),
+	}, nil
+}
+
+func (w *packageWriter) Write(_ string, files map[string][]byte) error {
+	meta, archive, err := chart.Package(files)
+	if err != nil {
+		return err
+	}
+
+	// Adding first rejects replacing a published archive with a different one.
+	if err := w.index.Add(meta, archive, w.url, w.created); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(w.output, 0o755); err != nil {
+		return errors.Wrap(err, "failed creating directory")
+	}
+
+	archivePath := filepath.Join(w.output, meta.ArchiveName())
+	return errors.Wrap(os.WriteFile(archivePath, archive, 0o644), "failed writing file: "+archivePath)
+}
+
+func (w *packageWriter) Published(name string) *chart.Metadata {
+	return w.index.Latest(name)
+}
+
+func (w *packageWriter) Close() error {
+	b, err := w.index.Marshal(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(w.output, 0o755); err != nil {
+		return errors.Wrap(err, "failed creating directory")
+	}
+
+	indexPath := filepath.Join(w.output, chart.IndexFile)
+	return errors.Wrap(os.WriteFile(indexPath, b, 0o644), "failed writing file: "+indexPath)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charrapp/charrapp/cache"
+	"github.com/charrapp/charrapp/lsio"
+	"github.com/charrapp/charrapp/ratelimit"
+	"github.com/charrapp/charrapp/source"
+)
+
+const (
+	defaultSource   = "lsio"
+	defaultRate     = 5
+	defaultCacheTTL = time.Hour
+
+	// httpTimeout bounds every request, so a stalled connection cannot hang a run.
+	httpTimeout = time.Minute
+)
+
+// sourceOptions configures the source opened by a command.
+type sourceOptions struct {
+	name     string
+	strict   bool
+	rate     float64
+	cacheDir string
+	cacheTTL time.Duration
+	offline  bool
+}
+
+var sources = map[string]func(opts *sourceOptions) source.Source{
+	"lsio": func(opts *sourceOptions) source.Source {
+		limiter := ratelimit.New(opts.rate)
+
+		var client lsio.HTTPClient = &http.Client{Transport: limiter.Transport(nil), Timeout: httpTimeout}
+		var refs lsio.RefLister = lsio.GitRefLister{Limiter: limiter}
+		if opts.cacheDir != "" {
+			c := cache.New(opts.cacheDir, opts.cacheTTL, opts.offline)
+			client = c.HTTPClient(&http.Client{Transport: limiter.Transport(nil), Timeout: httpTimeout})
+			refs = c.RefLister(refs)
+		}
+
+		lsioOpts := []lsio.Option{lsio.WithHTTPClient(client), lsio.WithRefLister(refs)}
+		if opts.strict {
+			lsioOpts = append(lsioOpts, lsio.WithStrictParsing())
+		}
+		return lsio.New(lsioOpts...)
+	},
+}
+
+// sourceFlags registers the flags selecting and configuring the image source.
+func sourceFlags(flags *flag.FlagSet) *sourceOptions {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	opts := &sourceOptions{}
+	flags.StringVar(&opts.name, "source", defaultSource, "image source to use, one of: "+strings.Join(names, ", "))
+	flags.Float64Var(&opts.rate, "rate", defaultRate, "maximum requests per second to each host, 0 to disable")
+	flags.StringVar(&opts.cacheDir, "cache-dir", defaultCacheDir(), "directory HTTP responses and git refs are cached in, empty to disable")
+	flags.DurationVar(&opts.cacheTTL, "cache-ttl", defaultCacheTTL, "age up to which cached entries are used without revalidation")
+	flags.BoolVar(&opts.offline, "offline", false, "serve everything from the cache and never access the network")
+	return opts
+}
+
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "charrapp")
+}
+
+func openSource(opts *sourceOptions) (source.Source, error) {
+	newSource, ok := sources[opts.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown source %q", opts.name)
+	}
+	if opts.offline && opts.cacheDir == "" {
+		return nil, fmt.Errorf("--offline requires a --cache-dir")
+	}
+	return newSource(opts), nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func runVersions(cmd *command, args []string) int {
+	flags := cmd.flagSet()
+	srcOpts := sourceFlags(flags)
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	src, err := openSource(srcOpts)
+	if err != nil {
+		return fail(err)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	versions, err := src.Versions(ctx, flags.Arg(0))
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, version := range versions {
+		fmt.Fprintf(os.Stdout, "%s\t%s\n", version.Raw, version.Semver)
+	}
+	return exitOK
+}
@@ -1,61 +1,114 @@
 package parser
 
 import (
-	"fmt"
+	"context"
+	"flag"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/MarvinJWendt/testza"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
 
 	"github.com/charrapp/charrapp/chart"
-	"github.com/charrapp/charrapp/lsio"
+	"github.com/charrapp/charrapp/lsio/lsiotest"
+	"github.com/charrapp/charrapp/source"
+	charttemplate "github.com/charrapp/charrapp/template"
 )
 
-const baseOut = "out"
+const (
+	fixtures = "lsio/testdata"
+	golden   = "testdata/golden"
+)
+
+var update = flag.Bool("update", false, "update the golden files in "+golden)
 
 func TestE2E(t *testing.T) {
-	images, err := lsio.GetAllImages()
+	src := lsiotest.New(fixtures)
+
+	images, err := src.Images(context.Background())
 	testza.AssertNoError(t, err)
+	testza.AssertNotZero(t, len(images))
 
 	for _, image := range images {
-		println("processing", image.Name)
-		writeOut(t, image)
+		image := image
+		t.Run(image, func(t *testing.T) {
+			assertGolden(t, src, image)
+		})
 	}
 }
 
-func writeOut(t *testing.T, img *lsio.Image) {
-	versions, err := img.Versions()
+func TestGoldenSchema(t *testing.T) {
+	dirs, err := os.ReadDir(golden)
 	testza.AssertNoError(t, err)
 
-	if len(versions) == 0 {
-		println("Found 0 versions!")
-		return
+	for _, dir := range dirs {
+		dir := filepath.Join(golden, dir.Name())
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			schema, err := os.ReadFile(filepath.Join(dir, chart.SchemaFile))
+			testza.AssertNoError(t, err)
+			b, err := os.ReadFile(filepath.Join(dir, "values.yaml"))
+			testza.AssertNoError(t, err)
+
+			var values map[string]interface{}
+			testza.AssertNoError(t, yaml.Unmarshal(b, &values))
+
+			result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewGoLoader(values))
+			testza.AssertNoError(t, err)
+			if err == nil {
+				testza.AssertTrue(t, result.Valid(), result.Errors())
+			}
+		})
 	}
+}
 
-	version := versions[len(versions)-1]
+func assertGolden(t *testing.T, src source.Source, image string) {
+	t.Helper()
 
-	config, err := img.Config(version.Raw)
+	chartData, err := source.ChartData(context.Background(), src, image)
 	testza.AssertNoError(t, err)
 	if err != nil {
 		return
 	}
 
-	ports, err := img.Ports(version.Raw)
+	files, err := chartData.GenerateChart(charttemplate.FS)
 	testza.AssertNoError(t, err)
 
-	chartData := chart.Data{
-		Config:  config,
-		Version: fmt.Sprintf("%d.%d.%d", version.Semver.Major(), version.Semver.Minor(), version.Semver.Patch()),
-		Ports:   ports,
+	dir := filepath.Join(golden, image)
+	if *update {
+		testza.AssertNoError(t, os.RemoveAll(dir))
+		for name, b := range files {
+			realPath := filepath.Join(dir, name)
+			testza.AssertNoError(t, os.MkdirAll(filepath.Dir(realPath), 0o755))
+			testza.AssertNoError(t, os.WriteFile(realPath, b, 0o644))
+		}
+		return
 	}
 
-	files, err := chartData.GenerateChart()
-	testza.AssertNoError(t, err)
+	expected := make(map[string]string)
+	err = filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		expected[filepath.ToSlash(rel)] = string(b)
+		return nil
+	})
+	testza.AssertNoError(t, err, "golden files missing, run go test -update")
 
+	actual := make(map[string]string, len(files))
 	for name, b := range files {
-		realPath := filepath.Join(baseOut, img.Name, name)
-		testza.AssertNoError(t, os.MkdirAll(filepath.Dir(realPath), 0o777))
-		testza.AssertNoError(t, os.WriteFile(realPath, b, 0o777))
+		actual[name] = string(b)
 	}
+
+	testza.AssertEqual(t, expected, actual)
 }
@@ -0,0 +1,79 @@
+package lsio
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/pkg/errors"
+
+	"github.com/charrapp/charrapp/ratelimit"
+)
+
+// HTTPClient performs the HTTP requests of a Source. *http.Client implements it.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// RefLister lists the references of a remote git repository.
+type RefLister interface {
+	List(ctx context.Context, url string) ([]*plumbing.Reference, error)
+}
+
+// Option configures a Source.
+type Option func(*Source)
+
+// WithHTTPClient sets the client used to fetch the fleet page and repository files.
+func WithHTTPClient(client HTTPClient) Option {
+	return func(s *Source) {
+		s.client = client
+	}
+}
+
+// WithRefLister sets the lister used to fetch the git references of image repositories.
+func WithRefLister(lister RefLister) Option {
+	return func(s *Source) {
+		s.refs = lister
+	}
+}
+
+// WithStrictParsing validates readme-vars files with parser.ParseStrict.
+func WithStrictParsing() Option {
+	return func(s *Source) {
+		s.strict = true
+	}
+}
+
+// GitRefLister lists references over the git smart HTTP protocol.
+type GitRefLister struct {
+	// Limiter spaces out the listings made to each host if set.
+	Limiter *ratelimit.Limiter
+}
+
+func (l GitRefLister) List(ctx context.Context, url string) ([]*plumbing.Reference, error) {
+	if ep, err := transport.NewEndpoint(url); err == nil {
+		if err := l.Limiter.Wait(ctx, ep.Host); err != nil {
+			return nil, errors.Wrap(err, "failed listing refs")
+		}
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{url},
+	})
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{
+		Auth:            nil,
+		InsecureSkipTLS: false,
+		CABundle:        nil,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed listing refs")
+	}
+
+	return refs, nil
+}
@@ -0,0 +1,110 @@
+package lsio
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/charrapp/charrapp/chart"
+)
+
+// instructions splits a Dockerfile into its instructions, joining continuation
+// lines and dropping comments.
+func instructions(dockerfile []byte) []string {
+	var out []string
+	var current strings.Builder
+	for _, line := range strings.Split(string(dockerfile), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") || (line == "" && current.Len() == 0) {
+			continue
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSuffix(line, "\\"))
+			current.WriteString(" ")
+			continue
+		}
+
+		current.WriteString(line)
+		if s := strings.TrimSpace(current.String()); s != "" {
+			out = append(out, s)
+		}
+		current.Reset()
+	}
+	return out
+}
+
+// parseHealthcheck returns the effective HEALTHCHECK of a Dockerfile, nil if
+// it has none or disables it with HEALTHCHECK NONE.
+func parseHealthcheck(dockerfile []byte) (*chart.Healthcheck, error) {
+	var healthcheck *chart.Healthcheck
+	for _, instruction := range instructions(dockerfile) {
+		fields := strings.Fields(instruction)
+		if !strings.EqualFold(fields[0], "HEALTHCHECK") {
+			continue
+		}
+
+		// Only the last HEALTHCHECK takes effect.
+		healthcheck = nil
+		args := strings.TrimSpace(instruction[len(fields[0]):])
+		if strings.EqualFold(args, "NONE") {
+			continue
+		}
+
+		h := &chart.Healthcheck{}
+		for strings.HasPrefix(args, "--") {
+			option, rest, _ := strings.Cut(args, " ")
+			args = strings.TrimSpace(rest)
+			if err := parseHealthcheckOption(h, option); err != nil {
+				return nil, err
+			}
+		}
+
+		cmd, command, ok := strings.Cut(args, " ")
+		if !ok || !strings.EqualFold(cmd, "CMD") {
+			return nil, fmt.Errorf("invalid HEALTHCHECK instruction: %s", instruction)
+		}
+		command = strings.TrimSpace(command)
+
+		if strings.HasPrefix(command, "[") {
+			if err := json.Unmarshal([]byte(command), &h.Command); err != nil {
+				return nil, errors.Wrap(err, "failed parsing HEALTHCHECK command")
+			}
+		} else {
+			h.Command = []string{"/bin/sh", "-c", command}
+		}
+		healthcheck = h
+	}
+	return healthcheck, nil
+}
+
+func parseHealthcheckOption(h *chart.Healthcheck, option string) error {
+	name, value, _ := strings.Cut(strings.TrimPrefix(option, "--"), "=")
+
+	if name == "retries" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.Wrap(err, "failed parsing HEALTHCHECK retries")
+		}
+		h.Retries = n
+		return nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return errors.Wrap(err, "failed parsing HEALTHCHECK "+name)
+	}
+	switch name {
+	case "interval":
+		h.Interval = d
+	case "timeout":
+		h.Timeout = d
+	case "start-period":
+		h.StartPeriod = d
+	}
+	return nil
+}
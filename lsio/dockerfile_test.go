@@ -0,0 +1,50 @@
+package lsio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/chart"
+)
+
+func TestParseHealthcheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		want       *chart.Healthcheck
+	}{
+		{"missing", "FROM alpine\nEXPOSE 80\n", nil},
+		{"none", "HEALTHCHECK CMD true\nHEALTHCHECK NONE\n", nil},
+		{
+			"shell form",
+			"FROM alpine\n# comment\nHEALTHCHECK --interval=1m --timeout=5s --retries=5 \\\n  CMD curl -f http://localhost/ || exit 1\n",
+			&chart.Healthcheck{
+				Command:  []string{"/bin/sh", "-c", "curl -f http://localhost/ || exit 1"},
+				Interval: time.Minute,
+				Timeout:  5 * time.Second,
+				Retries:  5,
+			},
+		},
+		{
+			"exec form",
+			`HEALTHCHECK --start-period=30s CMD ["/healthcheck.sh", "--quiet"]`,
+			&chart.Healthcheck{
+				Command:     []string{"/healthcheck.sh", "--quiet"},
+				StartPeriod: 30 * time.Second,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseHealthcheck([]byte(test.dockerfile))
+			testza.AssertNoError(t, err)
+			testza.AssertEqual(t, test.want, got)
+		})
+	}
+
+	_, err := parseHealthcheck([]byte("HEALTHCHECK --interval=often CMD true"))
+	testza.AssertNotNil(t, err)
+}
@@ -1,20 +1,22 @@
+// Package lsio implements a source.Source for the linuxserver.io image fleet.
 package lsio
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/config"
-	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/pkg/errors"
 
 	"github.com/charrapp/charrapp/chart"
 	"github.com/charrapp/charrapp/parser"
+	"github.com/charrapp/charrapp/source"
 	"github.com/charrapp/charrapp/utils"
 )
 
@@ -22,7 +24,17 @@ const (
 	lsioURL     = "https://fleet.linuxserver.io/?key=10:linuxserver"
 	lsioCR      = "lscr.io/linuxserver/"
 	rawTemplate = "https://raw.githubusercontent.com/linuxserver/docker-%s/%s/%s"
+	gitTemplate = "https://github.com/linuxserver/docker-%s"
 	udpSuffix   = "/udp"
+	dockerfile  = "Dockerfile"
+
+	// defaultLicense is the license of the linuxserver.io image repositories,
+	// used unless readme-vars sets project_license.
+	defaultLicense = "GPL-3.0-only"
+
+	extraGitHubRepo = "project_lsio_github_repo_url"
+	extraCategories = "project_categories"
+	extraLicense    = "project_license"
 )
 
 var (
@@ -31,52 +43,69 @@ var (
 	portRegex   = regexp.MustCompile(`(\d+)(\/tcp|\/udp)?`)
 )
 
-type Image struct {
-	Name     string
-	versions chart.VersionList
+var errNotFound = errors.New("404 Not Found")
+
+var _ source.Source = (*Source)(nil)
+
+// Source lists linuxserver.io images and fetches their metadata from GitHub.
+type Source struct {
+	client HTTPClient
+	refs   RefLister
+	strict bool
+
+	mu          sync.Mutex
+	versions    map[string]chart.VersionList
+	dockerfiles map[string]*dockerfileResult
 }
 
-func GetAllImages() ([]*Image, error) {
-	resp, err := http.Get(lsioURL)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed fetching lsio")
+// dockerfileResult is a fetched Dockerfile, or the error fetching it if it does not exist.
+type dockerfileResult struct {
+	body []byte
+	err  error
+}
+
+// New creates a linuxserver.io source. By default it uses http.DefaultClient
+// and lists git references over the network.
+func New(opts ...Option) *Source {
+	s := &Source{
+		client:      http.DefaultClient,
+		refs:        GitRefLister{},
+		versions:    make(map[string]chart.VersionList),
+		dockerfiles: make(map[string]*dockerfileResult),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
 
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+func (s *Source) Images(ctx context.Context) ([]string, error) {
+	body, err := s.get(ctx, lsioURL)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed reading response body")
+		return nil, errors.Wrap(err, "failed fetching lsio")
 	}
 
 	matches := urlRegex.FindAllSubmatch(body, -1)
 
-	images := make([]*Image, len(matches))
+	images := make([]string, len(matches))
 	for i, match := range matches {
-		images[i] = &Image{
-			Name: string(match[1]),
-		}
+		images[i] = string(match[1])
 	}
 
 	return images, nil
 }
 
-func (i *Image) Versions() ([]*chart.Version, error) {
-	if i.versions != nil {
-		return i.versions, nil
+func (s *Source) Versions(ctx context.Context, image string) (chart.VersionList, error) {
+	s.mu.Lock()
+	versions, ok := s.versions[image]
+	s.mu.Unlock()
+	if ok {
+		return versions, nil
 	}
 
-	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
-		Name: "origin",
-		URLs: []string{"https://github.com/linuxserver/docker-" + i.Name},
-	})
-
-	refs, err := remote.List(&git.ListOptions{
-		Auth:            nil,
-		InsecureSkipTLS: false,
-		CABundle:        nil,
-	})
+	refs, err := s.refs.List(ctx, fmt.Sprintf(gitTemplate, image))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed listing refs")
+		return nil, err
 	}
 
 	versionMap, err := utils.ExtractVersions(refs)
@@ -84,24 +113,61 @@ func (i *Image) Versions() ([]*chart.Version, error) {
 		return nil, err
 	}
 
-	i.versions = versionMap.Reduce()
-	i.versions.Sort()
+	versions = versionMap.Reduce()
+	versions.Sort()
+
+	s.mu.Lock()
+	s.versions[image] = versions
+	s.mu.Unlock()
 
-	return i.versions, nil
+	return versions, nil
+}
+
+func (s *Source) Repository(image string) string {
+	return lsioCR + image
+}
+
+func (s *Source) Project(image string, config *parser.Config) chart.Project {
+	project := chart.Project{License: defaultLicense, SourceURL: fmt.Sprintf(gitTemplate, image)}
+	if license, _ := config.Extras[extraLicense].(string); license != "" {
+		project.License = license
+	}
+	if repo, _ := config.Extras[extraGitHubRepo].(string); repo != "" {
+		project.SourceURL = repo
+	}
+
+	categories, _ := config.Extras[extraCategories].(string)
+	for _, category := range strings.Split(categories, ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			project.Categories = append(project.Categories, category)
+		}
+	}
+	return project
 }
 
-func (i *Image) URL() string {
-	return lsioCR + i.Name
+func (s *Source) fetch(ctx context.Context, image string, tag string, file string) ([]byte, error) {
+	return s.get(ctx, fmt.Sprintf(rawTemplate, image, tag, file))
 }
 
-func (i *Image) fetch(tag string, file string) ([]byte, error) {
-	url := fmt.Sprintf(rawTemplate, i.Name, tag, file)
-	resp, err := http.Get(url)
+func (s *Source) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating request")
+	}
+
+	resp, err := s.client.Do(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed fetching url: "+url)
 	}
 
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("failed fetching url: %s: %w", url, errNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed fetching url: %s: %s", url, resp.Status)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed reading body")
@@ -110,8 +176,8 @@ func (i *Image) fetch(tag string, file string) ([]byte, error) {
 	return body, nil
 }
 
-func (i *Image) Ports(version string) ([]*chart.ContainerPort, error) {
-	cfg, err := i.Config(version)
+func (s *Source) Ports(ctx context.Context, image string, version string) ([]*chart.ContainerPort, error) {
+	cfg, err := s.Config(ctx, image, version)
 	if err != nil {
 		return nil, err
 	}
@@ -119,13 +185,15 @@ func (i *Image) Ports(version string) ([]*chart.ContainerPort, error) {
 	ports := make([]*chart.ContainerPort, 0)
 	if cfg.ParamPorts != nil || cfg.OptParamPorts != nil {
 		for _, portList := range [][]parser.Port{cfg.ParamPorts, cfg.OptParamPorts} {
-			for _, port := range portList {
-				port, err := parsePort(port.InternalPort)
+			for _, param := range portList {
+				port, err := parsePort(param.InternalPort)
 				if err != nil {
 					return nil, err
 				}
 
 				if port != nil {
+					port.Name = param.Name
+					port.Description = param.PortDesc
 					ports = append(ports, port)
 				}
 			}
@@ -133,7 +201,10 @@ func (i *Image) Ports(version string) ([]*chart.ContainerPort, error) {
 		return ports, nil
 	}
 
-	body, err := i.fetch(version, "Dockerfile")
+	body, err := s.dockerfile(ctx, image, version)
+	if errors.Is(err, errNotFound) {
+		return ports, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -141,24 +212,65 @@ func (i *Image) Ports(version string) ([]*chart.ContainerPort, error) {
 	matches := exposeRegex.FindAllSubmatch(body, -1)
 
 	for _, match := range matches {
-		port, err := parsePort(string(match[1]))
-		if err != nil {
-			return nil, err
-		}
+		for _, field := range strings.Fields(string(match[1])) {
+			port, err := parsePort(field)
+			if err != nil {
+				return nil, err
+			}
 
-		if port != nil {
-			ports = append(ports, port)
+			if port != nil {
+				ports = append(ports, port)
+			}
 		}
 	}
 
 	return ports, nil
 }
 
-func (i *Image) Config(version string) (*parser.Config, error) {
-	body, err := i.fetch(version, "readme-vars.yml")
+// Healthcheck returns nil if the image has no Dockerfile at version.
+func (s *Source) Healthcheck(ctx context.Context, image string, version string) (*chart.Healthcheck, error) {
+	body, err := s.dockerfile(ctx, image, version)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
+	return parseHealthcheck(body)
+}
+
+// dockerfile fetches the Dockerfile of an image at version once, so ports
+// and health check do not request it twice. Only a missing Dockerfile is
+// remembered, other errors are retried on the next call.
+func (s *Source) dockerfile(ctx context.Context, image string, version string) ([]byte, error) {
+	key := image + ":" + version
+	s.mu.Lock()
+	result, ok := s.dockerfiles[key]
+	s.mu.Unlock()
+	if ok {
+		return result.body, result.err
+	}
+
+	body, err := s.fetch(ctx, image, version, dockerfile)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.dockerfiles[key] = &dockerfileResult{body: body, err: err}
+	s.mu.Unlock()
+
+	return body, err
+}
+
+func (s *Source) Config(ctx context.Context, image string, version string) (*parser.Config, error) {
+	body, err := s.fetch(ctx, image, version, "readme-vars.yml")
+	if err != nil {
+		return nil, err
+	}
+	if s.strict {
+		return parser.ParseStrict(bytes.NewReader(body))
+	}
 	return parser.Parse(bytes.NewReader(body))
 }
 
@@ -1,27 +1,138 @@
-package lsio
+package lsio_test
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/MarvinJWendt/testza"
+
+	"github.com/charrapp/charrapp/chart"
+	"github.com/charrapp/charrapp/lsio"
+	"github.com/charrapp/charrapp/lsio/lsiotest"
+)
+
+const (
+	fixtures    = "testdata"
+	plexVersion = "1.32.5.7349-8f4248874-ls185"
 )
 
 func TestImages(t *testing.T) {
-	images, err := GetAllImages()
+	src := lsiotest.New(fixtures)
+
+	images, err := src.Images(context.Background())
 	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []string{"plex", "sonarr", "wireguard"}, images)
 
-	plex := images[130]
+	plex := images[0]
 
-	versions, err := plex.Versions()
+	versions, err := src.Versions(context.Background(), plex)
 	testza.AssertNoError(t, err)
+	testza.AssertLen(t, versions, 1)
 
 	version := versions[0]
+	testza.AssertEqual(t, plexVersion, version.Raw)
+
+	ports, err := src.Ports(context.Background(), plex, version.Raw)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, ports, 10)
+	testza.AssertEqual(t, &chart.ContainerPort{Number: 32400, TCP: true}, ports[0])
+	testza.AssertEqual(t, &chart.ContainerPort{Number: 1900, TCP: false}, ports[1])
+
+	config, err := src.Config(context.Background(), plex, version.Raw)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "plex", config.ProjectName)
+}
+
+func TestPortsFromReadmeVars(t *testing.T) {
+	src := lsiotest.New(fixtures)
+
+	ports, err := src.Ports(context.Background(), "wireguard", "1.0.20210914-ls45")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []*chart.ContainerPort{
+		{Number: 51820, TCP: false, Description: "wireguard port"},
+	}, ports)
+}
+
+func TestMissingFile(t *testing.T) {
+	src := lsiotest.New(fixtures)
+
+	_, err := src.Config(context.Background(), "plex", "does-not-exist")
+	testza.AssertNotNil(t, err)
+}
+
+// countingClient counts the requests made for each URL.
+type countingClient struct {
+	lsio.HTTPClient
+	requests map[string]int
+}
+
+func (c *countingClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests[req.URL.String()]++
+	return c.HTTPClient.Do(req)
+}
+
+func TestDockerfileFetchedOnce(t *testing.T) {
+	client := &countingClient{
+		HTTPClient: &lsiotest.HTTPClient{Dir: filepath.Join(fixtures, "http")},
+		requests:   make(map[string]int),
+	}
+	src := lsio.New(lsio.WithHTTPClient(client), lsio.WithRefLister(&lsiotest.RefLister{Dir: filepath.Join(fixtures, "refs")}))
 
-	ports, err := plex.Ports(version.Raw)
+	_, err := src.Ports(context.Background(), "plex", plexVersion)
+	testza.AssertNoError(t, err)
+	_, err = src.Healthcheck(context.Background(), "plex", plexVersion)
 	testza.AssertNoError(t, err)
-	testza.AssertNotNil(t, ports)
 
-	config, err := plex.Config(version.Raw)
+	testza.AssertEqual(t, 1, client.requests["https://raw.githubusercontent.com/linuxserver/docker-plex/"+plexVersion+"/Dockerfile"])
+}
+
+func TestMissingDockerfile(t *testing.T) {
+	src := lsiotest.New(fixtures)
+
+	healthcheck, err := src.Healthcheck(context.Background(), "plex", "does-not-exist")
 	testza.AssertNoError(t, err)
-	testza.AssertNotNil(t, config)
+	testza.AssertNil(t, healthcheck)
+}
+
+// noDockerfileClient answers every Dockerfile request with 404 Not Found.
+type noDockerfileClient struct {
+	lsio.HTTPClient
+}
+
+func (c noDockerfileClient) Do(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/Dockerfile") {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("404: Not Found"))}, nil
+	}
+	return c.HTTPClient.Do(req)
+}
+
+func TestPortsMissingDockerfile(t *testing.T) {
+	client := noDockerfileClient{HTTPClient: &lsiotest.HTTPClient{Dir: filepath.Join(fixtures, "http")}}
+	src := lsio.New(lsio.WithHTTPClient(client), lsio.WithRefLister(&lsiotest.RefLister{Dir: filepath.Join(fixtures, "refs")}))
+
+	ports, err := src.Ports(context.Background(), "plex", plexVersion)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, ports, 0)
+}
+
+func TestProject(t *testing.T) {
+	src := lsiotest.New(fixtures)
+
+	config, err := src.Config(context.Background(), "plex", plexVersion)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, chart.Project{
+		License:   "GPL-3.0-only",
+		SourceURL: "https://github.com/linuxserver/docker-plex",
+	}, src.Project("plex", config))
+
+	config.Extras = map[string]interface{}{"project_license": "MIT", "project_categories": "Media Servers, Music"}
+	testza.AssertEqual(t, chart.Project{
+		License:    "MIT",
+		SourceURL:  "https://github.com/linuxserver/docker-plex",
+		Categories: []string{"Media Servers", "Music"},
+	}, src.Project("plex", config))
 }
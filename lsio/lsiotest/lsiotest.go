@@ -0,0 +1,120 @@
+// Package lsiotest provides a Source backed by recorded fixtures, so code using
+// lsio.Source can be tested without network access.
+//
+// A fixture directory contains the HTTP responses under http/<host>/<path>, where
+// a path ending in a slash is stored as index.html, and git reference listings
+// under refs/<host>/<path> in the format of git ls-remote: one "<hash> <ref>"
+// pair per line.
+package lsiotest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/charrapp/charrapp/lsio"
+)
+
+const indexFile = "index.html"
+
+// New returns a Source serving all requests from the fixtures in dir.
+func New(dir string) *lsio.Source {
+	return lsio.New(
+		lsio.WithHTTPClient(&HTTPClient{Dir: filepath.Join(dir, "http")}),
+		lsio.WithRefLister(&RefLister{Dir: filepath.Join(dir, "refs")}),
+	)
+}
+
+// HTTPClient answers GET requests with the files stored in Dir.
+// Requests for missing files are answered with 404 Not Found, requests
+// whose context is done fail with its error.
+type HTTPClient struct {
+	Dir string
+}
+
+func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	file, err := fixturePath(c.Dir, req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	status := http.StatusOK
+	body, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		status = http.StatusNotFound
+		body = []byte("404: Not Found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed reading fixture: %w", err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}, nil
+}
+
+// RefLister answers reference listings with the files stored in Dir.
+type RefLister struct {
+	Dir string
+}
+
+func (l *RefLister) List(ctx context.Context, rawURL string) ([]*plumbing.Reference, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	file, err := fixturePath(l.Dir, rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening fixture: %w", err)
+	}
+	defer f.Close()
+
+	var refs []*plumbing.Reference
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		refs = append(refs, plumbing.NewReferenceFromStrings(fields[1], fields[0]))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading fixture: %w", err)
+	}
+
+	return refs, nil
+}
+
+func fixturePath(dir string, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed parsing url: %w", err)
+	}
+
+	p := u.Path
+	if p == "" || strings.HasSuffix(p, "/") {
+		p += indexFile
+	}
+
+	return filepath.Join(dir, u.Host, filepath.FromSlash(p)), nil
+}
@@ -0,0 +1,186 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/noirbizarre/gonja"
+)
+
+// builtinContext holds the variables provided by the linuxserver.io templating
+// besides the keys of the readme-vars document itself.
+var builtinContext = map[string]interface{}{
+	"arch_x86_64": "x86-64",
+	"arch_arm64":  "arm64",
+	"arch_armhf":  "armhf",
+}
+
+var (
+	// templateBlockRegex matches Jinja expressions and statements.
+	templateBlockRegex = regexp.MustCompile(`{{.*?}}|{%.*?%}`)
+	identifierRegex    = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// interpolate renders every string field of config as a Jinja template. The
+// context holds all top-level keys of document, including unknown ones, whose
+// string values are themselves resolved in dependency order first. Unknown
+// keys are kept in config.Extras.
+func interpolate(config *Config, document map[string]interface{}) error {
+	ctx, err := resolveContext(document)
+	if err != nil {
+		return err
+	}
+
+	known := yamlFields(reflect.TypeOf(*config))
+	config.Extras = make(map[string]interface{})
+	for key := range document {
+		if _, ok := known[key]; !ok {
+			config.Extras[key] = ctx[key]
+		}
+	}
+
+	return interpolateRecursive(reflect.ValueOf(config), "", ctx)
+}
+
+// resolveContext renders the top-level string values of document so that
+// references between variables see rendered values, and reports cycles.
+func resolveContext(document map[string]interface{}) (gonja.Context, error) {
+	ctx := make(gonja.Context, len(builtinContext)+len(document))
+	for key, value := range builtinContext {
+		ctx[key] = value
+	}
+	for key, value := range document {
+		ctx[key] = value
+	}
+
+	keys := make([]string, 0, len(document))
+	for key := range document {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	const (
+		unvisited = iota
+		visiting
+		resolved
+	)
+	state := make(map[string]int, len(keys))
+	var path []string
+
+	var resolve func(key string) error
+	resolve = func(key string) error {
+		switch state[key] {
+		case resolved:
+			return nil
+		case visiting:
+			cycle := append(path[indexOf(path, key):], key)
+			return fmt.Errorf("interpolation cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		s, ok := document[key].(string)
+		if !ok || !isTemplate(s) {
+			state[key] = resolved
+			return nil
+		}
+
+		state[key] = visiting
+		path = append(path, key)
+		for _, dep := range references(s) {
+			if _, ok := document[dep]; !ok {
+				continue
+			}
+			if err := resolve(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+
+		out, err := executeTemplate(s, ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		ctx[key] = out
+		state[key] = resolved
+		return nil
+	}
+
+	for _, key := range keys {
+		if err := resolve(key); err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+// references returns the identifiers used in the template blocks of s.
+func references(s string) []string {
+	var refs []string
+	for _, block := range templateBlockRegex.FindAllString(s, -1) {
+		refs = append(refs, identifierRegex.FindAllString(block, -1)...)
+	}
+	return refs
+}
+
+func indexOf(s []string, v string) int {
+	for i := range s {
+		if s[i] == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func isTemplate(s string) bool {
+	return strings.Contains(s, "{{") || strings.Contains(s, "{%")
+}
+
+func interpolateRecursive(val reflect.Value, field string, ctx gonja.Context) error {
+	switch val.Kind() {
+	case reflect.Ptr:
+		return interpolateRecursive(val.Elem(), field, ctx)
+	case reflect.Slice:
+		for i := 0; i < val.Len(); i += 1 {
+			if err := interpolateRecursive(val.Index(i), fmt.Sprintf("%s[%d]", field, i), ctx); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < val.NumField(); i += 1 {
+			name := strings.Split(val.Type().Field(i).Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			if field != "" {
+				name = field + "." + name
+			}
+			if err := interpolateRecursive(val.Field(i), name, ctx); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		if !isTemplate(val.String()) {
+			return nil
+		}
+		newVal, err := executeTemplate(val.String(), ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		val.SetString(newVal)
+	}
+	return nil
+}
+
+func executeTemplate(s string, ctx gonja.Context) (string, error) {
+	tmpl, err := gonja.FromString(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+	result, err := tmpl.Execute(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+	return result, nil
+}
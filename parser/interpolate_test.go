@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+const interpolatedVars = `---
+project_name: plex
+project_url: "https://plex.tv"
+project_lsio_github_repo_url: "https://github.com/linuxserver/docker-{{ project_name }}"
+project_blurb: "{{ project_name|capitalize }} from {{ project_url }}"
+param_container_name: "{{ project_name }}"
+param_hostname: "{{ param_container_name }}.local"
+app_setup_block: "See {{ project_lsio_github_repo_url }} on {{ arch_x86_64 }}"
+param_env_vars:
+  - { env_var: "NAME", env_value: "{{ param_hostname }}", desc: "Defaults to {{ param_container_name }}" }
+`
+
+func TestInterpolate(t *testing.T) {
+	config, err := Parse(strings.NewReader(interpolatedVars))
+	testza.AssertNoError(t, err)
+
+	testza.AssertEqual(t, "Plex from https://plex.tv", config.ProjectBlurb)
+	testza.AssertEqual(t, "plex", config.ParamContainerName)
+	testza.AssertEqual(t, "plex.local", config.ParamHostname)
+	testza.AssertEqual(t, "See https://github.com/linuxserver/docker-plex on x86-64", config.AppSetupBlock)
+	testza.AssertEqual(t, "plex.local", config.ParamEnvVars[0].EnvValue)
+	testza.AssertEqual(t, "Defaults to plex", config.ParamEnvVars[0].Desc)
+
+	testza.AssertEqual(t, map[string]interface{}{
+		"project_lsio_github_repo_url": "https://github.com/linuxserver/docker-plex",
+	}, config.Extras)
+}
+
+func TestInterpolateCycle(t *testing.T) {
+	_, err := Parse(strings.NewReader(`---
+param_hostname: "{{ param_net }}"
+param_net: "{{ param_container_name }}"
+param_container_name: "{{ param_hostname }}"
+`))
+	testza.AssertNotNil(t, err)
+	testza.AssertContains(t, err.Error(), "interpolation cycle: param_container_name -> param_hostname -> param_net -> param_container_name")
+}
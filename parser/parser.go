@@ -3,11 +3,9 @@
 package parser
 
 import (
-	"fmt"
+	"bytes"
 	"io"
-	"reflect"
 
-	"github.com/noirbizarre/gonja"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 )
@@ -16,8 +14,8 @@ type Config struct {
 	ProjectName               string `yaml:"project_name"`
 	ProjectURL                string `yaml:"project_url"`
 	ProjectLogo               string `yaml:"project_logo"`
-	ProjectBlurb              string `yaml:"project_blurb" lsp:"true"`
-	ProjectRepoName           string `yaml:"project_repo_name" lsp:"true"`
+	ProjectBlurb              string `yaml:"project_blurb"`
+	ProjectRepoName           string `yaml:"project_repo_name"`
 	ProjectDeprecationStatus  bool   `yaml:"project_deprecation_status"`
 	ProjectDeprecationMessage string `yaml:"project_deprecation_message"`
 
@@ -28,7 +26,7 @@ type Config struct {
 	DevelopmentVersionsItems          []DevelopmentVersion `yaml:"development_versions_items"`
 
 	CommonParamEnvVarsEnabled   bool             `yaml:"common_param_env_vars_enabled"`
-	ParamContainerName          string           `yaml:"param_container_name" lsp:"true"`
+	ParamContainerName          string           `yaml:"param_container_name"`
 	ParamUsageIncludeHostname   bool             `yaml:"param_usage_include_hostname"`
 	ParamHostname               string           `yaml:"param_hostname"`
 	ParamHostnameDesc           string           `yaml:"param_hostname_desc"`
@@ -79,10 +77,13 @@ type Config struct {
 	ExternalApplicationComposeBlock   string           `yaml:"external_application_compose_block"`
 	ExternalApplicationUnraidBlock    string           `yaml:"external_application_unraid_block"`
 	Changelogs                        []Changelog      `yaml:"changelogs"`
+
+	// Extras holds the top-level keys not modelled by Config, with string values interpolated.
+	Extras map[string]interface{} `yaml:"-"`
 }
 
 type Architecture struct {
-	Arch string `yaml:"arch" lsp:"true"`
+	Arch string `yaml:"arch"`
 	Tag  string `yaml:"tag"`
 }
 
@@ -137,64 +138,23 @@ type Changelog struct {
 }
 
 func Parse(reader io.Reader) (*Config, error) {
+	b, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading yaml")
+	}
+
 	config := Config{}
-	if err := yaml.NewDecoder(reader).Decode(&config); err != nil {
+	if err := yaml.NewDecoder(bytes.NewReader(b)).Decode(&config); err != nil {
 		return nil, errors.Wrap(err, "failed decoding yaml")
 	}
-	if err := interpolate(&config); err != nil {
-		return nil, err
-	}
-	return &config, nil
-}
 
-func interpolate(config *Config) error {
-	return interpolateRecursive(reflect.ValueOf(config), nil, config)
-}
-
-func interpolateRecursive(val reflect.Value, typ *reflect.StructField, config *Config) error {
-	switch val.Kind() {
-	case reflect.Ptr:
-		return interpolateRecursive(val.Elem(), nil, config)
-	case reflect.Slice:
-		for i := 0; i < val.Len(); i += 1 {
-			if err := interpolateRecursive(val.Index(i), nil, config); err != nil {
-				return err
-			}
-		}
-	case reflect.Struct:
-		for i := 0; i < val.NumField(); i += 1 {
-			s := val.Type().Field(i)
-			if err := interpolateRecursive(val.Field(i), &s, config); err != nil {
-				return err
-			}
-		}
-	case reflect.String:
-		if typ != nil && typ.Tag.Get("lsp") != "true" {
-			return nil
-		}
-		newVal, err := executeTemplate(val.String(), config)
-		if err != nil {
-			return err
-		}
-		val.SetString(newVal)
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(b, &document); err != nil {
+		return nil, errors.Wrap(err, "failed decoding yaml")
 	}
-	return nil
-}
 
-func executeTemplate(s string, c *Config) (string, error) {
-	tmpl, err := gonja.FromString(s)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
-	}
-	result, err := tmpl.Execute(gonja.Context{
-		"project_name": c.ProjectName,
-		"project_url":  c.ProjectURL,
-		"arch_x86_64":  "x86-64",
-		"arch_arm64":   "arm64",
-		"arch_armhf":   "armhf",
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
+	if err := interpolate(&config, document); err != nil {
+		return nil, err
 	}
-	return result, nil
+	return &config, nil
 }
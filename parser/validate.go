@@ -0,0 +1,265 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// requiredFields must be present and non-empty in every readme-vars file.
+var requiredFields = []string{"project_name", "project_url"}
+
+// toggles maps boolean fields to the field which must be set when they are enabled.
+var toggles = []struct {
+	toggle string
+	field  string
+}{
+	{"project_blurb_optional_extras_enabled", "project_blurb_optional_extras"},
+	{"development_versions", "development_versions_items"},
+	{"param_usage_include_hostname", "param_hostname"},
+	{"param_usage_include_mac_address", "param_mac_address"},
+	{"param_usage_include_net", "param_net"},
+	{"param_usage_include_env", "param_env_vars"},
+	{"param_usage_include_vols", "param_volumes"},
+	{"param_usage_include_ports", "param_ports"},
+	{"param_device_map", "param_devices"},
+	{"cap_add_param", "cap_add_param_vars"},
+	{"security_opt_param", "security_opt_param_vars"},
+	{"opt_param_usage_include_env", "opt_param_env_vars"},
+	{"opt_param_usage_include_vols", "opt_param_volumes"},
+	{"opt_param_usage_include_ports", "opt_param_ports"},
+	{"opt_param_device_map", "opt_param_devices"},
+	{"opt_cap_add_param", "opt_cap_add_param_vars"},
+	{"opt_security_opt_param", "opt_security_opt_param_vars"},
+	{"optional_block_1", "optional_block_1_items"},
+	{"app_setup_block_enabled", "app_setup_block"},
+}
+
+// Diagnostic is a problem found while validating a readme-vars file.
+type Diagnostic struct {
+	Line    int
+	Column  int
+	Field   string
+	Message string
+}
+
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", d.Line, d.Column, d.Field, d.Message)
+}
+
+// ValidationError is returned by ParseStrict if the file has any diagnostics.
+type ValidationError struct {
+	Diagnostics []*Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		lines[i] = d.String()
+	}
+	return fmt.Sprintf("invalid readme-vars (%d problems):\n%s", len(e.Diagnostics), strings.Join(lines, "\n"))
+}
+
+// ParseStrict parses like Parse but first validates the file, failing with a
+// *ValidationError on unknown keys, type mismatches, missing required fields
+// and toggles enabled without their values.
+func ParseStrict(reader io.Reader) (*Config, error) {
+	b, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading yaml")
+	}
+
+	diagnostics, err := Validate(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	if len(diagnostics) > 0 {
+		return nil, &ValidationError{Diagnostics: diagnostics}
+	}
+
+	return Parse(bytes.NewReader(b))
+}
+
+// Validate checks a readme-vars file and returns all problems found, ordered
+// by their position in the file.
+func Validate(reader io.Reader) ([]*Diagnostic, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(reader).Decode(&doc); err != nil {
+		return nil, errors.Wrap(err, "failed decoding yaml")
+	}
+
+	root := &doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	v := &validator{}
+	v.check(root, reflect.TypeOf(Config{}), "")
+	if root.Kind == yaml.MappingNode {
+		v.checkRequired(root)
+		v.checkToggles(root)
+	}
+
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		a, b := v.diagnostics[i], v.diagnostics[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return v.diagnostics, nil
+}
+
+type validator struct {
+	diagnostics []*Diagnostic
+}
+
+func (v *validator) report(node *yaml.Node, field string, format string, args ...interface{}) {
+	v.diagnostics = append(v.diagnostics, &Diagnostic{
+		Line:    node.Line,
+		Column:  node.Column,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// check validates node against the Go type it is decoded into.
+func (v *validator) check(node *yaml.Node, typ reflect.Type, field string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			v.report(node, fieldName(field), "expected a mapping, got %s", describe(node))
+			return
+		}
+		fields := yamlFields(typ)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			path := joinField(field, key.Value)
+			f, ok := fields[key.Value]
+			if !ok {
+				v.report(key, path, "unknown key")
+				continue
+			}
+			v.check(value, f.Type, path)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			v.report(node, fieldName(field), "expected a list, got %s", describe(node))
+			return
+		}
+		for i, item := range node.Content {
+			v.check(item, typ.Elem(), fmt.Sprintf("%s[%d]", field, i))
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			v.report(node, fieldName(field), "expected a %s, got %s", typ.Kind(), describe(node))
+			return
+		}
+		if err := node.Decode(reflect.New(typ).Interface()); err != nil {
+			v.report(node, fieldName(field), "expected a %s, got %q", typ.Kind(), node.Value)
+		}
+	}
+}
+
+func (v *validator) checkRequired(root *yaml.Node) {
+	for _, field := range requiredFields {
+		key, value := lookup(root, field)
+		switch {
+		case key == nil:
+			v.report(root, field, "required key is missing")
+		case isEmpty(value):
+			v.report(value, field, "required key is empty")
+		}
+	}
+}
+
+func (v *validator) checkToggles(root *yaml.Node) {
+	for _, t := range toggles {
+		key, value := lookup(root, t.toggle)
+		if key == nil {
+			continue
+		}
+
+		var enabled bool
+		if err := value.Decode(&enabled); err != nil || !enabled {
+			continue
+		}
+
+		if _, fieldValue := lookup(root, t.field); fieldValue == nil || isEmpty(fieldValue) {
+			v.report(key, t.toggle, "is enabled but %s is empty", t.field)
+		}
+	}
+}
+
+// yamlFields maps the yaml keys of a struct type to its fields.
+func yamlFields(typ reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+func lookup(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func isEmpty(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Tag == "!!null" || node.Value == ""
+	case yaml.SequenceNode, yaml.MappingNode:
+		return len(node.Content) == 0
+	default:
+		return false
+	}
+}
+
+func describe(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+func joinField(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func fieldName(field string) string {
+	if field == "" {
+		return "<root>"
+	}
+	return field
+}
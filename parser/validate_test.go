@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+const invalidVars = `---
+project_name: ""
+project_repo_name: docker-test
+unknown_key: true
+param_usage_include_ports: true
+param_ports: []
+param_usage_include_vols: yes please
+param_volumes:
+  - { vol_path: "/config", vol_host_path: "/path", desc: "Config", size: 1Gi }
+param_env_vars: "PUID=1000"
+`
+
+func TestValidate(t *testing.T) {
+	diagnostics, err := Validate(strings.NewReader(invalidVars))
+	testza.AssertNoError(t, err)
+
+	actual := make([]string, len(diagnostics))
+	for i, d := range diagnostics {
+		actual[i] = d.String()
+	}
+
+	testza.AssertEqual(t, []string{
+		"2:1: project_url: required key is missing",
+		"2:15: project_name: required key is empty",
+		"4:1: unknown_key: unknown key",
+		"5:1: param_usage_include_ports: is enabled but param_ports is empty",
+		"7:27: param_usage_include_vols: expected a bool, got \"yes please\"",
+		"9:68: param_volumes[0].size: unknown key",
+		"10:17: param_env_vars: expected a list, got \"PUID=1000\"",
+	}, actual)
+}
+
+func TestParseStrict(t *testing.T) {
+	_, err := ParseStrict(strings.NewReader(testContent))
+
+	var validationErr *ValidationError
+	testza.AssertTrue(t, errors.As(err, &validationErr))
+
+	fields := make([]string, len(validationErr.Diagnostics))
+	for i, d := range validationErr.Diagnostics {
+		fields[i] = d.Field
+	}
+	testza.AssertEqual(t, []string{"project_lsio_github_repo_url", "optional_parameters"}, fields)
+}
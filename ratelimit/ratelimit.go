@@ -0,0 +1,80 @@
+// Package ratelimit spaces out requests made to the same host.
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limiter allows at most one request per interval to each host. It is safe
+// for concurrent use.
+type Limiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// New creates a limiter allowing perSecond requests per second to each host.
+// A limiter with a non-positive rate does not limit at all.
+func New(perSecond float64) *Limiter {
+	var interval time.Duration
+	if perSecond > 0 {
+		interval = time.Duration(float64(time.Second) / perSecond)
+	}
+
+	return &Limiter{
+		interval: interval,
+		next:     make(map[string]time.Time),
+	}
+}
+
+// Wait blocks until a request to host is allowed or ctx is done, in which
+// case it returns the error of ctx.
+func (l *Limiter) Wait(ctx context.Context, host string) error {
+	if l == nil || l.interval <= 0 {
+		return ctx.Err()
+	}
+
+	now := time.Now()
+
+	l.mu.Lock()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(slot.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Transport wraps next so every request waits for the limiter first.
+// A nil next uses http.DefaultTransport.
+func (l *Limiter) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{limiter: l, next: next}
+}
+
+type transport struct {
+	limiter *Limiter
+	next    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context(), req.URL.Host); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
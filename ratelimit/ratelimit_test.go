@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestLimiter(t *testing.T) {
+	l := New(100)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			testza.AssertNoError(t, l.Wait(context.Background(), "github.com"))
+		}()
+	}
+	wg.Wait()
+
+	// Five requests to one host need four intervals of 10ms.
+	testza.AssertTrue(t, time.Since(start) >= 40*time.Millisecond)
+
+}
+
+func TestLimiterHosts(t *testing.T) {
+	l := New(1)
+	testza.AssertNoError(t, l.Wait(context.Background(), "github.com"))
+
+	// Other hosts do not wait for the interval of a second.
+	start := time.Now()
+	testza.AssertNoError(t, l.Wait(context.Background(), "raw.githubusercontent.com"))
+	testza.AssertTrue(t, time.Since(start) < 500*time.Millisecond)
+}
+
+func TestLimiterCanceled(t *testing.T) {
+	l := New(1)
+	testza.AssertNoError(t, l.Wait(context.Background(), "github.com"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	testza.AssertEqual(t, context.DeadlineExceeded, l.Wait(ctx, "github.com"))
+	testza.AssertTrue(t, time.Since(start) < 500*time.Millisecond)
+}
@@ -0,0 +1,82 @@
+// Package source defines how images are discovered and how their metadata is fetched.
+package source
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/charrapp/charrapp/chart"
+	"github.com/charrapp/charrapp/parser"
+)
+
+// ErrNoVersions is returned when an image does not have any parsable versions.
+var ErrNoVersions = errors.New("found 0 versions")
+
+// Source is a publisher of container images which can be charted. Requests
+// made by its methods are aborted once their context is done.
+type Source interface {
+	// Images lists the names of all images provided by the source.
+	Images(ctx context.Context) ([]string, error)
+
+	// Versions lists all versions of an image, sorted in ascending order.
+	Versions(ctx context.Context, image string) (chart.VersionList, error)
+
+	// Config fetches and parses the readme-vars metadata of an image at the given version.
+	Config(ctx context.Context, image string, version string) (*parser.Config, error)
+
+	// Ports fetches the container ports exposed by an image at the given version.
+	Ports(ctx context.Context, image string, version string) ([]*chart.ContainerPort, error)
+
+	// Healthcheck fetches the health check of an image at the given version, nil if it has none.
+	Healthcheck(ctx context.Context, image string, version string) (*chart.Healthcheck, error)
+
+	// Repository returns the container repository an image is pulled from.
+	Repository(image string) string
+
+	// Project returns what the source knows about the project of an image beyond its config.
+	Project(image string, config *parser.Config) chart.Project
+}
+
+// ChartData collects everything needed to generate the chart of the latest version of an image.
+func ChartData(ctx context.Context, src Source, image string) (*chart.Data, error) {
+	versions, err := src.Versions(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, ErrNoVersions
+	}
+
+	version := versions[len(versions)-1]
+
+	config, err := src.Config(ctx, image, version.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	ports, err := src.Ports(ctx, image, version.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	healthcheck, err := src.Healthcheck(ctx, image, version.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	appVersion := fmt.Sprintf("%d.%d.%d", version.Semver.Major(), version.Semver.Minor(), version.Semver.Patch())
+	return &chart.Data{
+		Config:       config,
+		Version:      appVersion,
+		ChartVersion: appVersion,
+		Tag:          version.Raw,
+		Ports:        ports,
+		Healthcheck:  healthcheck,
+		Repository:   src.Repository(image),
+		Deprecated:   config.ProjectDeprecationStatus,
+		Project:      src.Project(image, config),
+	}, nil
+}
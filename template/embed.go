@@ -0,0 +1,10 @@
+// Package template embeds the default chart template tree.
+package template
+
+import "embed"
+
+// FS contains the default chart templates. Files ending in .gotmpl are
+// rendered with the chart data, all other files are copied as-is.
+//
+//go:embed *.gotmpl all:templates
+var FS embed.FS
@@ -0,0 +1,130 @@
+{{/*
+Expand the name of the chart.
+*/}}
+{{- define "app.name" -}}
+{{- default .Chart.Name .Values.nameOverride | trunc 63 | trimSuffix "-" }}
+{{- end }}
+
+{{/*
+Create a default fully qualified app name.
+We truncate at 63 chars because some Kubernetes name fields are limited to this (by the DNS naming spec).
+If release name contains chart name it will be used as a full name.
+*/}}
+{{- define "app.fullname" -}}
+{{- if .Values.fullnameOverride }}
+{{- .Values.fullnameOverride | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- $name := default .Chart.Name .Values.nameOverride }}
+{{- if contains $name .Release.Name }}
+{{- .Release.Name | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- printf "%s-%s" .Release.Name $name | trunc 63 | trimSuffix "-" }}
+{{- end }}
+{{- end }}
+{{- end }}
+
+{{/*
+Create chart name and version as used by the chart label.
+*/}}
+{{- define "app.chart" -}}
+{{- printf "%s-%s" .Chart.Name .Chart.Version | replace "+" "_" | trunc 63 | trimSuffix "-" }}
+{{- end }}
+
+{{/*
+Common labels
+*/}}
+{{- define "app.labels" -}}
+helm.sh/chart: {{ include "app.chart" . }}
+{{ include "app.selectorLabels" . }}
+{{- if .Chart.AppVersion }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+{{- end }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- end }}
+
+{{/*
+Selector labels
+*/}}
+{{- define "app.selectorLabels" -}}
+app.kubernetes.io/name: {{ include "app.name" . }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end }}
+
+{{/*
+Create the name of the service account to use
+*/}}
+{{- define "app.serviceAccountName" -}}
+{{- if .Values.serviceAccount.create }}
+{{- default (include "app.fullname" .) .Values.serviceAccount.name }}
+{{- else }}
+{{- default "default" .Values.serviceAccount.name }}
+{{- end }}
+{{- end }}
+
+{{/*
+Pod volumes for all enabled persistence entries and devices
+*/}}
+{{- define "app.volumes" -}}
+{{- $fullName := include "app.fullname" . }}
+{{- range $name, $p := .Values.persistence }}
+{{- if $p.enabled }}
+- name: {{ $name }}
+  {{- if eq $p.type "hostPath" }}
+  hostPath:
+    path: {{ required (printf "persistence.%s.hostPath is required for type hostPath" $name) $p.hostPath }}
+  {{- else if eq $p.type "emptyDir" }}
+  emptyDir: {}
+  {{- else }}
+  persistentVolumeClaim:
+    claimName: {{ $p.existingClaim | default (printf "%s-%s" $fullName $name) }}
+  {{- end }}
+{{- end }}
+{{- end }}
+{{- range $name, $d := .Values.devices }}
+{{- if $d.enabled }}
+- name: {{ include "app.deviceVolumeName" $name }}
+  hostPath:
+    path: {{ $d.hostPath }}
+    type: {{ $d.type }}
+{{- end }}
+{{- end }}
+{{- end }}
+
+{{/*
+Container volume mounts for all enabled persistence entries and devices
+*/}}
+{{- define "app.volumeMounts" -}}
+{{- range $name, $p := .Values.persistence }}
+{{- if $p.enabled }}
+- name: {{ $name }}
+  mountPath: {{ $p.mountPath }}
+{{- end }}
+{{- end }}
+{{- range $name, $d := .Values.devices }}
+{{- if $d.enabled }}
+- name: {{ include "app.deviceVolumeName" $name }}
+  mountPath: {{ $d.path }}
+{{- end }}
+{{- end }}
+{{- end }}
+
+{{/*
+Number of the entry in ports named by .name, failing with .value in the message if there is none
+*/}}
+{{- define "app.servicePort" -}}
+{{- $name := .name }}
+{{- $port := "" }}
+{{- range .root.Values.ports }}
+{{- if eq .name $name }}
+{{- $port = .port }}
+{{- end }}
+{{- end }}
+{{- required (printf "%s must name an entry of ports" .value) $port }}
+{{- end }}
+
+{{/*
+Volume name of a device, truncated to the 63 characters of a DNS label
+*/}}
+{{- define "app.deviceVolumeName" -}}
+{{- printf "device-%s" . | trunc 63 | trimSuffix "-" }}
+{{- end }}